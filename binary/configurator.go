@@ -1,12 +1,21 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io/fs"
+	"log"
+	"maps"
+	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/elastic/edot-collector-configurator/binary/pkg/configurator"
 	"github.com/goccy/go-yaml"
 )
 
@@ -19,8 +28,24 @@ func main() {
 	switch args[1] {
 	case "build":
 		buildRecipe(args)
+	case "build-all":
+		buildAllRecipes(args)
 	case "info":
 		printRecipeInfo(args)
+	case "list":
+		printComponentsList()
+	case "lint":
+		lintRecipe(args)
+	case "explain":
+		explainRecipe(args)
+	case "diff":
+		diffRecipe(args)
+	case "test":
+		runTests(args)
+	case "schema":
+		printFileSchema(args)
+	case "serve":
+		serve(args)
 	case "help":
 		printHelpMessage()
 	default:
@@ -34,8 +59,25 @@ USAGE
   configurator [subcommand]
 
 SUBCOMMANDS
-  info   path/to/recipe.yml                       Displays information about the provided recipe and its arguments.
-  build  path/to/recipe.yml [-output=otel.yml]    Builds a configuration based on the recipe file provided.
+  info    path/to/recipe.yml                       Displays information about the provided recipe, its arguments and components.
+  list                                             Lists the available components and their metadata.
+  lint    path/to/recipe.yml [-format=text]        Checks the recipe against best-practice rules. Output formats: text, json, sarif.
+  explain path/to/recipe.yml [path]                Shows where each value of the built configuration came from, optionally only under the given path.
+  diff    path/to/recipe.yml -against=otel.yml     Compares the recipe's configuration with an existing one. Exits with 1 when they differ.
+          [-format=text]                           Output formats: text, json.
+  test    [-update] [paths...]                     Runs the test cases of recipes (against their golden files) and components.
+  schema  recipe|component                         Prints the JSON Schema of recipe or component files, for editor completion and validation.
+  serve   [-listen=:8080]                          Serves an HTTP API listing recipes and components and building recipes from POSTed args.
+  build   path/to/recipe.yml [-output=otel.yml]    Builds a configuration based on the recipe file provided.
+          [-explain]                               Prints where each value came from, as the explain command does.
+          [-collector-version=9.2.0]               Warns about components not available in the targeted EDOT Collector version.
+          [-prune]                                 Leaves out the components that are never referenced.
+          [-annotate]                              Adds comments with the recipe, args (secrets redacted) and source of each component.
+          [-schemas=path/to/schemas]               Validates components without a schema against '<kind>/<type>.schema.json' files.
+          [-watch] [-exec=command]                 Rebuilds on every change of the recipe or its components, then runs the command.
+  build-all dir|glob -out-dir=out                  Builds every recipe within the directory or matching the glob, in parallel.
+          [-manifest=manifest.yml]                 Maps recipes to values files providing their args.
+          [-parallel=N]                            Number of recipes built at the same time, defaults to the number of CPUs.
 `
 
 func printHelpMessage() {
@@ -52,38 +94,93 @@ func buildRecipe(args []string) {
 
 	fs := flag.NewFlagSet("build", flag.ExitOnError)
 	outputPath := fs.String("output", "otel.yml", "Output YAML file path")
+	explain := fs.Bool("explain", false, "Prints where each value of the configuration came from, like the explain command")
+	collectorVersion := fs.String("collector-version", "", "The targeted EDOT Collector version, overrides the recipe's collector_version")
+	prune := fs.Bool("prune", false, "Leaves out the components that are never referenced")
+	annotate := fs.Bool("annotate", false, "Adds comments describing where the configuration and each of its components came from")
+	schemasDirPath := fs.String("schemas", "", "Directory with '<kind>/<type>.schema.json' files for components that don't declare a schema")
+	watch := fs.Bool("watch", false, "Rebuilds the configuration every time the recipe or one of its components changes")
+	command := fs.String("exec", "", "With -watch, a shell command to run after every successful rebuild")
 
-	flagSetArgs := []string{}
-	recipeArgs := make(map[string]string)
-	for k, v := range recipe.Args {
-		fs.Func("A"+k, v.Description, func(s string) error {
-			recipeArgs[k] = s
-			return nil
-		})
+	recipeArgs := parseRecipeArgs(fs, &recipe, args[3:])
+	if *command != "" && !*watch {
+		exitOnError(fmt.Errorf("-exec can only be used along with -watch"))
 	}
-	if len(args) > 3 {
-		flagSetArgs = append(flagSetArgs, args[3:]...)
+	if *explain && *watch {
+		exitOnError(fmt.Errorf("-explain can't be used along with -watch"))
 	}
-	fs.Parse(flagSetArgs)
-
-	configuration, err := BuildRecipe(&recipe, RecipeParams{
-		Args:              recipeArgs,
-		ComponentsDirPath: getComponentsDirPath(),
-	})
 
-	checkUnexpectedError(err)
+	options := configurator.BuildOptions{
+		Args:             recipeArgs,
+		Components:       getComponentsFS(),
+		CollectorVersion: *collectorVersion,
+		RecipePath:       args[2],
+		Annotate:         *annotate,
+		PruneUnused:      *prune,
+		Warn:             printWarning,
+	}
+	if *schemasDirPath != "" {
+		options.Schemas = os.DirFS(*schemasDirPath)
+	}
+	if *watch {
+		watchRecipe(args[2], *outputPath, *command, *schemasDirPath, options)
+		return
+	}
+	configuration, err := configurator.BuildRecipeYaml(&recipe, options)
+	exitOnError(err)
 	saveConfiguration(configuration, *outputPath)
+	if *explain {
+		options.Warn = nil
+		printExplanations(&recipe, options, "")
+	}
 }
 
-func saveConfiguration(configuration map[string]any, outputPath string) {
-	yamlData, err := yaml.Marshal(configuration)
-	checkUnexpectedError(err)
+func buildAllRecipes(args []string) {
+	if len(args) < 3 {
+		exitOnError(fmt.Errorf("you must provide the recipes directory or glob"))
+	}
+	fs := flag.NewFlagSet("build-all", flag.ExitOnError)
+	outDir := fs.String("out-dir", "", "Directory the configurations are written to")
+	manifestPath := fs.String("manifest", "", "YAML file mapping recipes to values files providing their args")
+	parallelism := fs.Int("parallel", runtime.NumCPU(), "Number of recipes built at the same time")
+	collectorVersion := fs.String("collector-version", "", "The targeted EDOT Collector version, overrides the recipes' collector_version")
+	prune := fs.Bool("prune", false, "Leaves out the components that are never referenced")
+	annotate := fs.Bool("annotate", false, "Adds comments describing where the configurations and each of their components came from")
+	schemasDirPath := fs.String("schemas", "", "Directory with '<kind>/<type>.schema.json' files for components that don't declare a schema")
+	fs.Parse(args[3:])
+	if *outDir == "" {
+		printError(fmt.Errorf("you must provide the output directory via -out-dir"))
+		os.Exit(2)
+	}
+
+	jobs, err := configurator.PlanBuildJobs(args[2], *outDir, *manifestPath)
+	if err != nil {
+		printError(err)
+		os.Exit(2)
+	}
+	options := configurator.BuildOptions{
+		Components:       getComponentsFS(),
+		CollectorVersion: *collectorVersion,
+		Annotate:         *annotate,
+		PruneUnused:      *prune,
+	}
+	if *schemasDirPath != "" {
+		options.Schemas = os.DirFS(*schemasDirPath)
+	}
+	results := configurator.BuildAll(jobs, options, *parallelism)
+	fmt.Print(configurator.FormatBuildResults(results))
+	if configurator.HasBuildFailures(results) {
+		os.Exit(1)
+	}
+}
+
+func saveConfiguration(yamlData []byte, outputPath string) {
 	f, err := os.Create(outputPath)
-	checkUnexpectedError(err)
+	exitOnError(err)
 	defer f.Close()
 
 	_, err = f.Write(yamlData)
-	checkUnexpectedError(err)
+	exitOnError(err)
 }
 
 func getComponentsDirPath() string {
@@ -92,11 +189,21 @@ func getComponentsDirPath() string {
 	return filepath.Join(filepath.Dir(filepath.Dir(executable)), "components")
 }
 
+func getComponentsFS() fs.FS {
+	return os.DirFS(getComponentsDirPath())
+}
+
+func getRecipesDirPath() string {
+	return filepath.Join(filepath.Dir(getComponentsDirPath()), "recipes")
+}
+
 var infoTemplate = `
 DESCRIPTION
 %s
 ARGUMENTS
 %s
+COMPONENTS
+%s
 `
 
 func printRecipeInfo(args []string) {
@@ -120,9 +227,319 @@ func printRecipeInfo(args []string) {
 		if v.Env != "" {
 			argsDescription += fmt.Sprintf(" (ENV var '%s')", v.Env)
 		}
+		if v.Default != "" && !recipe.IsSecretPlaceholder("$args."+k) {
+			argsDescription += fmt.Sprintf(" (default '%s')", v.Default)
+		}
 		argsDescription += "\n"
 	}
-	fmt.Printf(infoTemplate, indentStr(recipe.Description, 2), argsDescription)
+	fmt.Printf(infoTemplate, indentStr(recipe.Description, 2), argsDescription, describeRecipeComponents(&recipe))
+}
+
+func describeRecipeComponents(recipe *configurator.Recipe) string {
+	componentsFS := getComponentsFS()
+	var rows [][]string
+	for _, k := range slices.Sorted(maps.Keys(recipe.Components)) {
+		source := recipe.Components[k].Source
+		component, err := configurator.LoadComponent(componentsFS, source)
+		if err != nil {
+			rows = append(rows, []string{k, source, fmt.Sprintf("(could not load component: %v)", err)})
+			continue
+		}
+		kind, typeName := component.Metadata.KindAndType(source)
+		rows = append(rows, []string{k, source, kind + "/" + typeName, component.Metadata.Summary()})
+	}
+	return formatTable(rows)
+}
+
+func lintRecipe(args []string) {
+	err := checkRecipeProvided(args)
+	if err != nil {
+		printError(err)
+		return
+	}
+	recipePath := args[2]
+	recipe := getRecipe(recipePath)
+
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	format := fs.String("format", "text", "Output format: text, json or sarif")
+	recipeArgs := parseRecipeArgs(fs, &recipe, args[3:])
+
+	var unprovidedArgs []string
+	for _, k := range slices.Sorted(maps.Keys(recipe.Args)) {
+		if _, ok := recipeArgs[k]; !ok && os.Getenv(recipe.Args[k].Env) == "" && recipe.Args[k].Default == "" {
+			recipeArgs[k] = "<" + k + ">"
+			unprovidedArgs = append(unprovidedArgs, k)
+		}
+	}
+	configuration, err := configurator.BuildRecipe(&recipe, configurator.BuildOptions{
+		Args:       recipeArgs,
+		Components: getComponentsFS(),
+		Warn:       func(string) {},
+	})
+	if err != nil && len(unprovidedArgs) > 0 {
+		err = fmt.Errorf("%w\nthe args that weren't provided were replaced by placeholder values, provide them to lint the recipe: %s", err, strings.Join(unprovidedArgs, ", "))
+	}
+	exitOnError(err)
+	params := configurator.LintParams{UnprovidedArgs: unprovidedArgs}
+	rules, err := configurator.LintRules(&recipe, params)
+	exitOnError(err)
+	findings, err := configurator.Lint(&recipe, configuration, params)
+	exitOnError(err)
+	output, err := configurator.FormatLintFindings(findings, rules, *format, recipePath)
+	exitOnError(err)
+	fmt.Print(string(output))
+	if configurator.HasLintErrors(findings) {
+		os.Exit(1)
+	}
+}
+
+func explainRecipe(args []string) {
+	err := checkRecipeProvided(args)
+	if err != nil {
+		printError(err)
+		return
+	}
+	recipe := getRecipe(args[2])
+	recipeArgs := args[3:]
+	filter := ""
+	if len(recipeArgs) > 0 && !strings.HasPrefix(recipeArgs[0], "-") {
+		filter = recipeArgs[0]
+		recipeArgs = recipeArgs[1:]
+	}
+
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	printExplanations(&recipe, configurator.BuildOptions{
+		Args:       parseRecipeArgs(fs, &recipe, recipeArgs),
+		Components: getComponentsFS(),
+		RecipePath: args[2],
+		Warn:       printWarning,
+	}, filter)
+}
+
+func printExplanations(recipe *configurator.Recipe, options configurator.BuildOptions, filter string) {
+	var resolutions []configurator.Resolution
+	options.Trace = func(r configurator.Resolution) {
+		resolutions = append(resolutions, r)
+	}
+	configuration, err := configurator.BuildRecipe(recipe, options)
+	exitOnError(err)
+	explanations, err := configurator.Explain(configuration, resolutions, filter)
+	exitOnError(err)
+	fmt.Print(configurator.FormatExplanations(explanations, recipe.IsSecretPlaceholder))
+}
+
+func diffRecipe(args []string) {
+	err := checkRecipeProvided(args)
+	if err != nil {
+		printError(err)
+		os.Exit(2)
+	}
+	recipe := getRecipe(args[2])
+
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	againstPath := fs.String("against", "", "Path to the existing configuration to compare with")
+	format := fs.String("format", "text", "Output format: text or json")
+	recipeArgs := parseRecipeArgs(fs, &recipe, args[3:])
+	if *againstPath == "" {
+		printError(fmt.Errorf("you must provide the configuration to compare with via -against"))
+		os.Exit(2)
+	}
+
+	built, err := configurator.BuildRecipeYaml(&recipe, configurator.BuildOptions{
+		Args:       recipeArgs,
+		Components: getComponentsFS(),
+		Warn:       printWarning,
+	})
+	exitOnError(err)
+	var builtConfiguration any
+	checkUnexpectedError(yaml.Unmarshal(built, &builtConfiguration))
+	deployed, err := os.ReadFile(*againstPath)
+	exitOnError(err)
+	var deployedConfiguration any
+	err = yaml.Unmarshal(deployed, &deployedConfiguration)
+	if err != nil {
+		exitOnError(fmt.Errorf("invalid configuration '%s': %w", *againstPath, err))
+	}
+
+	entries := configurator.Diff(deployedConfiguration, builtConfiguration)
+	output, err := configurator.FormatDiff(entries, *format)
+	exitOnError(err)
+	fmt.Print(string(output))
+	if len(entries) > 0 {
+		os.Exit(1)
+	}
+}
+
+func runTests(args []string) {
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	update := fs.Bool("update", false, "Regenerate the golden files of the recipes instead of comparing with them")
+	fs.Parse(args[2:])
+	componentsDirPath := getComponentsDirPath()
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{relativeToWorkingDir(getRecipesDirPath()), relativeToWorkingDir(componentsDirPath)}
+	}
+
+	componentsFS := getComponentsFS()
+	var writeGolden func(path string, data []byte) error
+	var results []configurator.TestResult
+	var uncovered []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		checkUnexpectedError(err)
+		if dirPath, ok := pathWithin(componentsDirPath, path); ok {
+			pathResults, pathUncovered, err := configurator.RunComponentTests(componentsFS, dirPath)
+			checkUnexpectedError(err)
+			results = append(results, pathResults...)
+			uncovered = append(uncovered, pathUncovered...)
+			continue
+		}
+		// Recipes are read relative to the recipes directory, or to the given path when it's outside of it.
+		root := getRecipesDirPath()
+		recipePath, ok := pathWithin(root, path)
+		if !ok {
+			root, recipePath = path, "."
+			if !info.IsDir() {
+				root, recipePath = filepath.Dir(path), filepath.Base(path)
+			}
+		}
+		recipesFS := os.DirFS(root)
+		if *update {
+			writeGolden = func(path string, data []byte) error {
+				return os.WriteFile(filepath.Join(root, filepath.FromSlash(path)), data, 0644)
+			}
+		}
+		if info.IsDir() {
+			testCases, pathUncovered, err := configurator.DiscoverRecipeTestCases(recipesFS, recipePath)
+			checkUnexpectedError(err)
+			results = append(results, configurator.RunRecipeTests(recipesFS, testCases, componentsFS, writeGolden)...)
+			uncovered = append(uncovered, pathUncovered...)
+			continue
+		}
+		testCases, err := configurator.FindRecipeTestCases(recipesFS, recipePath)
+		checkUnexpectedError(err)
+		if len(testCases) == 0 {
+			uncovered = append(uncovered, recipePath)
+		}
+		results = append(results, configurator.RunRecipeTests(recipesFS, testCases, componentsFS, writeGolden)...)
+	}
+
+	fmt.Print(configurator.FormatTestResults(results, uncovered))
+	if configurator.HasTestFailures(results) {
+		os.Exit(1)
+	}
+}
+
+func serve(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := fs.String("listen", ":8080", "The address to listen on")
+	schemasDirPath := fs.String("schemas", "", "Directory with '<kind>/<type>.schema.json' files for components that don't declare a schema")
+	fs.Parse(args[2:])
+
+	options := configurator.ServerOptions{
+		Recipes:    os.DirFS(getRecipesDirPath()),
+		Components: getComponentsFS(),
+		Log: func(message string) {
+			log.Print(message)
+		},
+	}
+	if *schemasDirPath != "" {
+		options.Schemas = os.DirFS(*schemasDirPath)
+	}
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           configurator.NewHandler(options),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("listening on %s", *listen)
+	checkUnexpectedError(server.ListenAndServe())
+}
+
+// pathWithin returns the slash-separated path of path within dir, if it's within it.
+func pathWithin(dir string, path string) (string, bool) {
+	absolutePath, err := filepath.Abs(path)
+	checkUnexpectedError(err)
+	relative, err := filepath.Rel(dir, absolutePath)
+	if err != nil || !filepath.IsLocal(relative) {
+		return "", false
+	}
+	return filepath.ToSlash(relative), true
+}
+
+func relativeToWorkingDir(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	relative, err := filepath.Rel(wd, path)
+	if err != nil {
+		return path
+	}
+	return relative
+}
+
+func printFileSchema(args []string) {
+	if len(args) < 3 {
+		printError(fmt.Errorf("you must provide the schema name: recipe or component"))
+		return
+	}
+	schema, err := configurator.FileSchema(args[2])
+	if err != nil {
+		printError(err)
+		return
+	}
+	output, err := json.MarshalIndent(schema, "", "  ")
+	checkUnexpectedError(err)
+	fmt.Println(string(output))
+}
+
+func parseRecipeArgs(fs *flag.FlagSet, recipe *configurator.Recipe, args []string) map[string]string {
+	recipeArgs := make(map[string]string)
+	for k, v := range recipe.Args {
+		fs.Func("A"+k, v.Description, func(s string) error {
+			recipeArgs[k] = s
+			return nil
+		})
+	}
+	fs.Parse(args)
+	return recipeArgs
+}
+
+func printComponentsList() {
+	files, err := configurator.ListComponents(getComponentsFS())
+	checkUnexpectedError(err)
+	groups := make(map[string][][]string)
+	for _, file := range files {
+		kind, typeName := file.Component.Metadata.KindAndType(file.Source)
+		groups[kind] = append(groups[kind], []string{typeName, file.Source, file.Component.Metadata.Summary()})
+	}
+	for _, kind := range slices.Sorted(maps.Keys(groups)) {
+		fmt.Printf("\n%s\n%s", strings.ToUpper(kind), formatTable(groups[kind]))
+	}
+}
+
+func formatTable(rows [][]string) string {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+	result := ""
+	for _, row := range rows {
+		line := " "
+		for i, cell := range row {
+			line += " " + cell
+			if i < len(row)-1 {
+				line += strings.Repeat(" ", widths[i]-len(cell)+2)
+			}
+		}
+		result += strings.TrimRight(line, " ") + "\n"
+	}
+	return result
 }
 
 func indentStr(value string, level int) string {
@@ -140,13 +557,13 @@ func checkRecipeProvided(args []string) error {
 	return nil
 }
 
-func getRecipe(recipeFilePath string) recipeType {
+func getRecipe(recipeFilePath string) configurator.Recipe {
 	f, err := os.Open(recipeFilePath)
-	checkUnexpectedError(err)
+	exitOnError(err)
 	defer f.Close()
 
-	recipe, err := ParseRecipe(f)
-	checkUnexpectedError(err)
+	recipe, err := configurator.ParseRecipe(f)
+	exitOnError(err)
 	return recipe
 }
 
@@ -156,6 +573,18 @@ func checkUnexpectedError(err error) {
 	}
 }
 
+func printWarning(message string) {
+	fmt.Fprintf(os.Stderr, "warning: %s\n", message)
+}
+
 func printError(err error) {
 	fmt.Fprintf(os.Stderr, "error: %v\n", err)
 }
+
+// exitOnError reports errors caused by the user's input, which don't deserve the stack trace of checkUnexpectedError.
+func exitOnError(err error) {
+	if err != nil {
+		printError(err)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,63 @@
+package configurator
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+var configuratorVersion = "dev"
+
+var secretArgPattern = regexp.MustCompile(`(?i)(key|token|password|secret|credential)`)
+
+const redactedValue = "<redacted>"
+
+func annotationHeader(recipe *Recipe, params BuildOptions, buildTime time.Time) (string, error) {
+	argsRefs, err := getArgsRefs(recipe.Args, params.Args, params.IgnoreEnv)
+	if err != nil {
+		return "", err
+	}
+	var header strings.Builder
+	fmt.Fprintf(&header, "# Generated by the EDOT Collector Configurator, version %s.\n", configuratorVersion)
+	if params.RecipePath != "" {
+		fmt.Fprintf(&header, "# Recipe: %s\n", params.RecipePath)
+	}
+	fmt.Fprintf(&header, "# Built at: %s\n", buildTime.UTC().Format(time.RFC3339))
+	if len(recipe.Args) > 0 {
+		header.WriteString("# Args:\n")
+		for _, k := range slices.Sorted(maps.Keys(recipe.Args)) {
+			value := argsRefs["$args."+k]
+			if recipe.Args[k].isSecret(k) {
+				value = redactedValue
+			}
+			fmt.Fprintf(&header, "#   %s: %s\n", k, value)
+		}
+	}
+	return header.String(), nil
+}
+
+func (a Arg) isSecret(name string) bool {
+	return a.Secret || secretArgPattern.MatchString(name)
+}
+
+func componentComments(recipe *Recipe, components map[string]*loadedComponent) yaml.CommentMap {
+	comments := make(yaml.CommentMap)
+	for _, k := range recipe.declaredComponentKeys() {
+		component, ok := components[k]
+		if !ok {
+			continue
+		}
+		configurations := component.Definition.Configurations
+		if len(configurations) == 0 {
+			configurations = []string{"default"}
+		}
+		comment := fmt.Sprintf(" From recipe component '%s': %s, configurations: %s", k, component.Definition.Source, strings.Join(configurations, ", "))
+		comments["$."+JoinPath([]string{component.Kind, component.Name})] = []*yaml.Comment{yaml.HeadComment(comment)}
+	}
+	return comments
+}
@@ -0,0 +1,122 @@
+package configurator
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotationHeader(t *testing.T) {
+	recipe := &Recipe{
+		Args: map[string]Arg{
+			"endpoint":      {Description: "Endpoint"},
+			"api_key":       {Description: "Guessed secret from its name"},
+			"bearer":        {Description: "Explicit secret", Secret: true},
+			"from_env_only": {Description: "Env arg", Env: "TEST_ANNOTATION_ENV"},
+		},
+	}
+	t.Setenv("TEST_ANNOTATION_ENV", "env value")
+
+	header, err := annotationHeader(recipe, BuildOptions{
+		RecipePath: "recipes/test.yml",
+		Args: map[string]string{
+			"endpoint": "http://localhost:9200",
+			"api_key":  "very-secret",
+			"bearer":   "also-secret",
+		},
+	}, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, `# Generated by the EDOT Collector Configurator, version dev.
+# Recipe: recipes/test.yml
+# Built at: 2025-01-02T03:04:05Z
+# Args:
+#   api_key: <redacted>
+#   bearer: <redacted>
+#   endpoint: http://localhost:9200
+#   from_env_only: env value
+`, header)
+}
+
+func TestBuildRecipeYamlAnnotated(t *testing.T) {
+	componentsDir := writeFiles(t, map[string]string{
+		"receivers/otlp.yml": `
+configurations:
+  default:
+    content:
+      protocols: {}
+`,
+		"exporters/debug.yml": `
+configurations:
+  default:
+    content: {}
+  detailed:
+    content:
+      verbosity: detailed
+`,
+	})
+	recipe, err := ParseRecipe(strings.NewReader(`
+description: Annotations test
+args:
+  token:
+    description: A token
+components:
+  otlp:
+    source: receivers/otlp.yml
+  debug:
+    source: exporters/debug.yml
+    name: verbose
+    configurations: [ default, detailed ]
+service:
+  pipelines:
+    logs:
+      receivers: [ $components.otlp ]
+      exporters: [ $components.debug ]
+`))
+	assert.NoError(t, err)
+	params := BuildOptions{
+		Args:       map[string]string{"token": "secret-token"},
+		Components: os.DirFS(componentsDir),
+		RecipePath: "recipe.yml",
+		Warn:       func(string) {},
+	}
+
+	plain, err := BuildRecipeYaml(&recipe, params)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(plain), "#")
+
+	params.Annotate = true
+	annotated, err := BuildRecipeYaml(&recipe, params)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(annotated), "secret-token")
+	assert.Equal(t, `# Generated by the EDOT Collector Configurator, version dev.
+# Recipe: recipe.yml
+# Built at: <time>
+# Args:
+#   token: <redacted>
+receivers:
+  # From recipe component 'otlp': receivers/otlp.yml, configurations: default
+  otlp:
+    protocols: {}
+exporters:
+  # From recipe component 'debug': exporters/debug.yml, configurations: default, detailed
+  debug/verbose:
+    verbosity: detailed
+service:
+  pipelines:
+    logs:
+      receivers:
+      - otlp
+      exporters:
+      - debug/verbose
+`, regexp.MustCompile(`Built at: .+`).ReplaceAllString(string(annotated), "Built at: <time>"))
+
+	var plainValue, annotatedValue any
+	assert.NoError(t, yaml.Unmarshal(plain, &plainValue))
+	assert.NoError(t, yaml.Unmarshal(annotated, &annotatedValue))
+	assert.Equal(t, plainValue, annotatedValue)
+}
@@ -0,0 +1,266 @@
+package configurator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-yaml"
+)
+
+// BuildJob is a recipe to build with BuildAll, along with its args and the file its configuration is written to.
+type BuildJob struct {
+	RecipePath string
+	OutputPath string
+	Args       map[string]string
+}
+
+type BuildJobResult struct {
+	Job      BuildJob
+	Warnings []string
+	Err      error
+}
+
+type manifestFile struct {
+	Recipes map[string]string
+}
+
+type valuesFile struct {
+	Args map[string]string
+}
+
+// PlanBuildJobs returns a job for every recipe within the pattern's directory, or matching the pattern when it's a
+// glob. Their configurations are written to outDir, keeping their paths relative to the directory (or the glob's
+// base directory). The manifest, when provided, maps recipe paths to the values files providing their args, both
+// relative to the manifest's directory.
+func PlanBuildJobs(pattern string, outDir string, manifestPath string) ([]BuildJob, error) {
+	baseDir, recipePaths, err := findRecipes(pattern)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if manifestPath != "" {
+		values, err = loadManifest(manifestPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	jobs := make([]BuildJob, 0, len(recipePaths))
+	for _, recipePath := range recipePaths {
+		relativePath, err := filepath.Rel(baseDir, recipePath)
+		if err != nil {
+			return nil, err
+		}
+		job := BuildJob{
+			RecipePath: recipePath,
+			OutputPath: filepath.Join(outDir, relativePath),
+			Args:       make(map[string]string),
+		}
+		absolutePath, err := filepath.Abs(recipePath)
+		if err != nil {
+			return nil, err
+		}
+		if valuesPath, ok := values[absolutePath]; ok {
+			job.Args, err = loadValues(valuesPath)
+			if err != nil {
+				return nil, err
+			}
+			delete(values, absolutePath)
+		}
+		jobs = append(jobs, job)
+	}
+	var errs []error
+	for _, recipePath := range slices.Sorted(maps.Keys(values)) {
+		errs = append(errs, fmt.Errorf("the manifest recipe '%s' doesn't match any of the recipes to build", recipePath))
+	}
+	return jobs, errors.Join(errs...)
+}
+
+func findRecipes(pattern string) (string, []string, error) {
+	var recipePaths []string
+	if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+		err := filepath.WalkDir(pattern, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && d.IsDir() && strings.HasSuffix(d.Name(), recipeTestsDirSuffix) {
+				return filepath.SkipDir
+			}
+			if err != nil || d.IsDir() || !yamlFileNamePattern.MatchString(d.Name()) {
+				return err
+			}
+			recipePaths = append(recipePaths, path)
+			return nil
+		})
+		return pattern, recipePaths, err
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, path := range matches {
+		if info, err := os.Stat(path); err != nil || info.IsDir() || isWithinRecipeTestsDir(path) {
+			continue
+		}
+		recipePaths = append(recipePaths, path)
+	}
+	if len(recipePaths) == 0 {
+		return "", nil, fmt.Errorf("no recipes match '%s'", pattern)
+	}
+	return globBaseDir(pattern), recipePaths, nil
+}
+
+func isWithinRecipeTestsDir(path string) bool {
+	return slices.ContainsFunc(strings.Split(filepath.Dir(path), string(filepath.Separator)), func(element string) bool {
+		return strings.HasSuffix(element, recipeTestsDirSuffix)
+	})
+}
+
+func globBaseDir(pattern string) string {
+	var base []string
+	for _, element := range strings.Split(filepath.Dir(pattern), string(filepath.Separator)) {
+		if strings.ContainsAny(element, `*?[\`) {
+			break
+		}
+		base = append(base, element)
+	}
+	if len(base) == 0 {
+		return "."
+	}
+	if base[0] == "" {
+		base[0] = string(filepath.Separator)
+	}
+	return filepath.Join(base...)
+}
+
+func loadManifest(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest manifestFile
+	if err = yaml.UnmarshalWithOptions(data, &manifest, yaml.DisallowUnknownField()); err != nil {
+		return nil, fmt.Errorf("invalid manifest '%s': %w", path, err)
+	}
+	manifestDir := filepath.Dir(path)
+	values := make(map[string]string, len(manifest.Recipes))
+	for recipePath, valuesPath := range manifest.Recipes {
+		absolutePath, err := filepath.Abs(filepath.Join(manifestDir, recipePath))
+		if err != nil {
+			return nil, err
+		}
+		values[absolutePath] = filepath.Join(manifestDir, valuesPath)
+	}
+	return values, nil
+}
+
+func loadValues(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var values valuesFile
+	if err = yaml.UnmarshalWithOptions(data, &values, yaml.DisallowUnknownField()); err != nil {
+		return nil, fmt.Errorf("invalid values file '%s': %w", path, err)
+	}
+	if values.Args == nil {
+		values.Args = make(map[string]string)
+	}
+	return values.Args, nil
+}
+
+// BuildAll builds the jobs, up to parallelism at a time, and writes their configurations. The builds share
+// options.ComponentCache, or a new cache of options.Components when it's not set, so that each component file is
+// parsed once. The results are in the order of the jobs.
+func BuildAll(jobs []BuildJob, options BuildOptions, parallelism int) []BuildJobResult {
+	if options.ComponentCache == nil {
+		options.ComponentCache = NewComponentCache(options.Components)
+	}
+	results := make([]BuildJobResult, len(jobs))
+	semaphore := make(chan struct{}, max(parallelism, 1))
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			results[i] = runBuildJob(job, options)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func runBuildJob(job BuildJob, options BuildOptions) BuildJobResult {
+	result := BuildJobResult{Job: job}
+	options.Args = job.Args
+	options.RecipePath = job.RecipePath
+	options.Warn = func(message string) {
+		result.Warnings = append(result.Warnings, message)
+	}
+	result.Err = func() error {
+		recipeFile, err := os.Open(job.RecipePath)
+		if err != nil {
+			return err
+		}
+		defer recipeFile.Close()
+		recipe, err := ParseRecipe(recipeFile)
+		if err != nil {
+			return err
+		}
+		var errs []error
+		for _, name := range slices.Sorted(maps.Keys(job.Args)) {
+			if _, ok := recipe.Args[name]; !ok {
+				errs = append(errs, fmt.Errorf("unknown arg '%s'", name))
+			}
+		}
+		if len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+		data, err := BuildRecipeYaml(&recipe, options)
+		var argErr *ArgError
+		if errors.As(err, &argErr) {
+			return fmt.Errorf("arg '%s' not provided - you may provide via the env var: '%s' or via the recipe's values file in the manifest", argErr.Name, argErr.Env)
+		}
+		if err != nil {
+			return err
+		}
+		if err = os.MkdirAll(filepath.Dir(job.OutputPath), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(job.OutputPath, data, 0644)
+	}()
+	return result
+}
+
+func FormatBuildResults(results []BuildJobResult) string {
+	var text strings.Builder
+	succeeded, failed := 0, 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(&text, "FAIL    %s\n", result.Job.RecipePath)
+			for _, line := range strings.Split(result.Err.Error(), "\n") {
+				fmt.Fprintf(&text, "  error: %s\n", line)
+			}
+		} else {
+			succeeded++
+			fmt.Fprintf(&text, "OK      %s -> %s\n", result.Job.RecipePath, result.Job.OutputPath)
+		}
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(&text, "  warning: %s\n", warning)
+		}
+	}
+	fmt.Fprintf(&text, "\n%d succeeded, %d failed\n", succeeded, failed)
+	return text.String()
+}
+
+func HasBuildFailures(results []BuildJobResult) bool {
+	return slices.ContainsFunc(results, func(result BuildJobResult) bool {
+		return result.Err != nil
+	})
+}
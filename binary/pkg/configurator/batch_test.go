@@ -0,0 +1,151 @@
+package configurator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildAll(t *testing.T) {
+	componentsDir := writeFiles(t, map[string]string{
+		"receivers/otlp.yml": `
+vars:
+  port:
+    required: true
+configurations:
+  default:
+    content:
+      endpoint: localhost:$vars.port
+`,
+		"exporters/debug.yml": `
+configurations:
+  default:
+    content:
+      verbosity: basic
+`,
+	})
+	recipe := `
+description: Test recipe
+args:
+  port:
+    description: The port
+    default: "4317"
+components:
+  otlp:
+    source: receivers/otlp.yml
+    vars:
+      port: $args.port
+  debug:
+    source: exporters/debug.yml
+service:
+  pipelines:
+    traces:
+      receivers: [ $components.otlp ]
+      exporters: [ $components.debug ]
+`
+	dir := writeFiles(t, map[string]string{
+		"recipes/eu/gateway.yml":            recipe,
+		"recipes/us/gateway.yml":            recipe,
+		"recipes/us/gateway.tests/case.yml": "args: {}",
+		"recipes/us/edge.yaml":              strings.Replace(recipe, `default: "4317"`, "env: TEST_BATCH_PORT", 1),
+		"recipes/broken.yml":                `description: Broken recipe`,
+		"manifest.yml": `
+recipes:
+  recipes/us/gateway.yml: values/us.yml
+`,
+		"values/us.yml": `
+args:
+  port: "4318"
+`,
+	})
+	outDir := filepath.Join(dir, "out")
+
+	jobs, err := PlanBuildJobs(filepath.Join(dir, "recipes"), outDir, filepath.Join(dir, "manifest.yml"))
+	assert.NoError(t, err)
+	assert.Equal(t, []BuildJob{
+		{RecipePath: filepath.Join(dir, "recipes/broken.yml"), OutputPath: filepath.Join(outDir, "broken.yml"), Args: map[string]string{}},
+		{RecipePath: filepath.Join(dir, "recipes/eu/gateway.yml"), OutputPath: filepath.Join(outDir, "eu/gateway.yml"), Args: map[string]string{}},
+		{RecipePath: filepath.Join(dir, "recipes/us/edge.yaml"), OutputPath: filepath.Join(outDir, "us/edge.yaml"), Args: map[string]string{}},
+		{RecipePath: filepath.Join(dir, "recipes/us/gateway.yml"), OutputPath: filepath.Join(outDir, "us/gateway.yml"), Args: map[string]string{"port": "4318"}},
+	}, jobs)
+
+	results := BuildAll(jobs, BuildOptions{Components: os.DirFS(componentsDir), IgnoreEnv: true}, 2)
+	assert.True(t, HasBuildFailures(results))
+	assert.ErrorContains(t, results[0].Err, "Args")
+	assert.NoError(t, results[1].Err)
+	assert.EqualError(t, results[2].Err, "arg 'port' not provided - you may provide via the env var: 'TEST_BATCH_PORT' or via the recipe's values file in the manifest")
+	assert.NoError(t, results[3].Err)
+	eu, err := os.ReadFile(filepath.Join(outDir, "eu/gateway.yml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(eu), "endpoint: localhost:4317")
+	us, err := os.ReadFile(filepath.Join(outDir, "us/gateway.yml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(us), "endpoint: localhost:4318")
+	assert.NoFileExists(t, filepath.Join(outDir, "broken.yml"))
+
+	output := FormatBuildResults([]BuildJobResult{results[1], results[3]})
+	assert.Equal(t, "OK      "+jobs[1].RecipePath+" -> "+jobs[1].OutputPath+"\n"+
+		"OK      "+jobs[3].RecipePath+" -> "+jobs[3].OutputPath+"\n"+
+		"\n2 succeeded, 0 failed\n", output)
+
+	jobs, err = PlanBuildJobs(filepath.Join(dir, "recipes/*/gateway.yml"), outDir, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []BuildJob{
+		{RecipePath: filepath.Join(dir, "recipes/eu/gateway.yml"), OutputPath: filepath.Join(outDir, "eu/gateway.yml"), Args: map[string]string{}},
+		{RecipePath: filepath.Join(dir, "recipes/us/gateway.yml"), OutputPath: filepath.Join(outDir, "us/gateway.yml"), Args: map[string]string{}},
+	}, jobs)
+
+	jobs, err = PlanBuildJobs(filepath.Join(dir, "recipes/*/*"), outDir, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []BuildJob{
+		{RecipePath: filepath.Join(dir, "recipes/eu/gateway.yml"), OutputPath: filepath.Join(outDir, "eu/gateway.yml"), Args: map[string]string{}},
+		{RecipePath: filepath.Join(dir, "recipes/us/edge.yaml"), OutputPath: filepath.Join(outDir, "us/edge.yaml"), Args: map[string]string{}},
+		{RecipePath: filepath.Join(dir, "recipes/us/gateway.yml"), OutputPath: filepath.Join(outDir, "us/gateway.yml"), Args: map[string]string{}},
+	}, jobs)
+
+	_, err = PlanBuildJobs(filepath.Join(dir, "recipes/us/*/*"), outDir, "")
+	assert.EqualError(t, err, "no recipes match '"+filepath.Join(dir, "recipes/us/*/*")+"'")
+
+	_, err = PlanBuildJobs(filepath.Join(dir, "recipes/eu/*.yml"), outDir, filepath.Join(dir, "manifest.yml"))
+	assert.EqualError(t, err, "the manifest recipe '"+filepath.Join(dir, "recipes/us/gateway.yml")+"' doesn't match any of the recipes to build")
+
+	_, err = PlanBuildJobs(filepath.Join(dir, "recipes/*/missing.yml"), outDir, "")
+	assert.EqualError(t, err, "no recipes match '"+filepath.Join(dir, "recipes/*/missing.yml")+"'")
+}
+
+func TestBuildAllErrors(t *testing.T) {
+	results := []BuildJobResult{
+		{Job: BuildJob{RecipePath: "recipes/a.yml", OutputPath: "out/a.yml"}, Warnings: []string{"arg 'unused' is never used"}},
+		{Job: BuildJob{RecipePath: "recipes/b.yml", OutputPath: "out/b.yml"}, Err: &ArgError{Name: "port", Env: "PORT"}},
+	}
+	assert.Equal(t, `OK      recipes/a.yml -> out/a.yml
+  warning: arg 'unused' is never used
+FAIL    recipes/b.yml
+  error: arg 'port' not provided - you may provide via the env var: 'PORT' or via the command line argument: '-Aport'
+
+1 succeeded, 1 failed
+`, FormatBuildResults(results))
+	assert.True(t, HasBuildFailures(results))
+	assert.False(t, HasBuildFailures(results[:1]))
+}
+
+func TestGlobBaseDir(t *testing.T) {
+	for _, tc := range []struct {
+		testName string
+		pattern  string
+		expected string
+	}{
+		{testName: "file glob", pattern: "recipes/*.yml", expected: "recipes"},
+		{testName: "directory glob", pattern: "recipes/gateway/*/otlp.yml", expected: "recipes/gateway"},
+		{testName: "no glob", pattern: "recipes/gateway/test/otlp.yml", expected: "recipes/gateway/test"},
+		{testName: "glob at the root", pattern: "*/gateway.yml", expected: "."},
+		{testName: "absolute path", pattern: "/tmp/recipes/[ab]/*.yml", expected: "/tmp/recipes"},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			assert.Equal(t, tc.expected, globBaseDir(tc.pattern))
+		})
+	}
+}
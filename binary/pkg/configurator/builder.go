@@ -0,0 +1,196 @@
+package configurator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/goccy/go-yaml"
+)
+
+// RecipeBuilder constructs a recipe programmatically. The recipe it builds goes through the same parsing and
+// validation as recipe files, and can be written as one with MarshalRecipe.
+type RecipeBuilder struct {
+	recipe Recipe
+	errs   []error
+}
+
+// ComponentHandle references a component added to a RecipeBuilder.
+type ComponentHandle struct {
+	key string
+}
+
+// Pipeline lists the components of a service pipeline.
+type Pipeline struct {
+	Receivers  []ComponentHandle
+	Processors []ComponentHandle
+	Exporters  []ComponentHandle
+}
+
+func NewRecipeBuilder(description string) *RecipeBuilder {
+	return &RecipeBuilder{
+		recipe: Recipe{
+			Description: description,
+			Args:        make(map[string]Arg),
+			Components:  make(map[string]RecipeComponent),
+			Service:     make(map[string]any),
+		},
+	}
+}
+
+func (h ComponentHandle) Key() string {
+	return h.key
+}
+
+// Reference returns the placeholder that resolves to the component's name, e.g. within other components' vars.
+func (h ComponentHandle) Reference() string {
+	return "$components." + h.key
+}
+
+// AddArg declares an arg and returns the placeholder that resolves to its value.
+func (b *RecipeBuilder) AddArg(name string, arg Arg) string {
+	if _, ok := b.recipe.Args[name]; ok {
+		b.errs = append(b.errs, fmt.Errorf("arg '%s' is already declared", name))
+	}
+	b.recipe.Args[name] = arg
+	return "$args." + name
+}
+
+// AddConst declares a const and returns the placeholder that resolves to its value.
+func (b *RecipeBuilder) AddConst(name string, value any) string {
+	if b.recipe.Const == nil {
+		b.recipe.Const = make(map[string]any)
+	}
+	if _, ok := b.recipe.Const[name]; ok {
+		b.errs = append(b.errs, fmt.Errorf("const '%s' is already declared", name))
+	}
+	b.recipe.Const[name] = value
+	return "$const." + name
+}
+
+func (b *RecipeBuilder) AddComponent(key string, component RecipeComponent) ComponentHandle {
+	if _, ok := b.recipe.Components[key]; ok {
+		b.errs = append(b.errs, fmt.Errorf("component '%s' is already declared", key))
+	} else {
+		b.recipe.componentsOrder = append(b.recipe.componentsOrder, key)
+	}
+	b.recipe.Components[key] = component
+	return ComponentHandle{key: key}
+}
+
+func (b *RecipeBuilder) AddPipeline(id string, pipeline Pipeline) {
+	pipelines, _ := b.recipe.Service["pipelines"].(map[string]any)
+	if pipelines == nil {
+		pipelines = make(map[string]any)
+		b.recipe.Service["pipelines"] = pipelines
+	}
+	if _, ok := pipelines[id]; ok {
+		b.errs = append(b.errs, fmt.Errorf("pipeline '%s' is already declared", id))
+	}
+	definition := make(map[string]any)
+	for _, section := range []struct {
+		name    string
+		handles []ComponentHandle
+	}{
+		{"receivers", pipeline.Receivers},
+		{"processors", pipeline.Processors},
+		{"exporters", pipeline.Exporters},
+	} {
+		if len(section.handles) > 0 {
+			definition[section.name] = b.references(section.handles)
+		}
+	}
+	pipelines[id] = definition
+}
+
+func (b *RecipeBuilder) AddExtension(extension ComponentHandle) {
+	extensions, _ := b.recipe.Service["extensions"].([]any)
+	b.recipe.Service["extensions"] = append(extensions, b.references([]ComponentHandle{extension})...)
+}
+
+func (b *RecipeBuilder) SetCollectorVersion(version string) {
+	b.recipe.CollectorVersion = version
+}
+
+func (b *RecipeBuilder) DisableLintRules(ids ...string) {
+	b.recipe.Lint.Disable = append(b.recipe.Lint.Disable, ids...)
+}
+
+func (b *RecipeBuilder) references(handles []ComponentHandle) []any {
+	references := make([]any, 0, len(handles))
+	for _, handle := range handles {
+		if _, ok := b.recipe.Components[handle.key]; !ok {
+			b.errs = append(b.errs, fmt.Errorf("component '%s' isn't declared in this recipe", handle.key))
+		}
+		references = append(references, handle.Reference())
+	}
+	return references
+}
+
+// Build returns the recipe, as parsed from its YAML representation so that it matches a recipe file exactly.
+func (b *RecipeBuilder) Build() (Recipe, error) {
+	if err := errors.Join(b.errs...); err != nil {
+		return Recipe{}, err
+	}
+	data, err := MarshalRecipe(&b.recipe)
+	if err != nil {
+		return Recipe{}, err
+	}
+	return ParseRecipe(bytes.NewReader(data))
+}
+
+// MarshalRecipe writes the recipe as a recipe file, keeping the order in which its components were declared.
+func MarshalRecipe(recipe *Recipe) ([]byte, error) {
+	ordered := yaml.MapSlice{{Key: "description", Value: recipe.Description}}
+	if recipe.CollectorVersion != "" {
+		ordered = append(ordered, yaml.MapItem{Key: "collector_version", Value: recipe.CollectorVersion})
+	}
+	if len(recipe.Lint.Disable) > 0 {
+		ordered = append(ordered, yaml.MapItem{Key: "lint", Value: yaml.MapSlice{{Key: "disable", Value: recipe.Lint.Disable}}})
+	}
+	args := yaml.MapSlice{}
+	for _, name := range slices.Sorted(maps.Keys(recipe.Args)) {
+		args = append(args, yaml.MapItem{Key: name, Value: marshalArg(recipe.Args[name])})
+	}
+	ordered = append(ordered, yaml.MapItem{Key: "args", Value: args})
+	if len(recipe.Const) > 0 {
+		ordered = append(ordered, yaml.MapItem{Key: "const", Value: orderMap(recipe.Const, nil)})
+	}
+	components := yaml.MapSlice{}
+	for _, key := range recipe.declaredComponentKeys() {
+		components = append(components, yaml.MapItem{Key: key, Value: marshalRecipeComponent(recipe.Components[key])})
+	}
+	ordered = append(ordered, yaml.MapItem{Key: "components", Value: components})
+	ordered = append(ordered, yaml.MapItem{Key: "service", Value: orderService(recipe.Service)})
+	return yaml.MarshalWithOptions(ordered, yaml.UseLiteralStyleIfMultiline(true))
+}
+
+func marshalArg(arg Arg) yaml.MapSlice {
+	ordered := yaml.MapSlice{{Key: "description", Value: arg.Description}}
+	if arg.Env != "" {
+		ordered = append(ordered, yaml.MapItem{Key: "env", Value: arg.Env})
+	}
+	if arg.Default != "" {
+		ordered = append(ordered, yaml.MapItem{Key: "default", Value: arg.Default})
+	}
+	if arg.Secret {
+		ordered = append(ordered, yaml.MapItem{Key: "secret", Value: true})
+	}
+	return ordered
+}
+
+func marshalRecipeComponent(component RecipeComponent) yaml.MapSlice {
+	ordered := yaml.MapSlice{{Key: "source", Value: component.Source}}
+	if component.Name != "" {
+		ordered = append(ordered, yaml.MapItem{Key: "name", Value: component.Name})
+	}
+	if len(component.Configurations) > 0 {
+		ordered = append(ordered, yaml.MapItem{Key: "configurations", Value: component.Configurations})
+	}
+	if len(component.Vars) > 0 {
+		ordered = append(ordered, yaml.MapItem{Key: "vars", Value: orderMap(component.Vars, nil)})
+	}
+	return ordered
+}
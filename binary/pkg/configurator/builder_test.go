@@ -0,0 +1,198 @@
+package configurator
+
+import (
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var builtRecipeYaml = `description: |
+  Receives OTLP data and exports it to an OTLP endpoint.
+lint:
+  disable:
+  - no-debug-exporter
+args:
+  endpoint:
+    description: The OTLP endpoint
+    env: OTLP_ENDPOINT
+    default: localhost:4317
+  token:
+    description: The OTLP token
+    secret: true
+const:
+  port: 4318
+components:
+  otlp-receiver:
+    source: receivers/otlp.yml
+    configurations:
+    - http
+    vars:
+      port: $const.port
+  auth:
+    source: extensions/auth.yml
+    vars:
+      token: $args.token
+  otlp-exporter:
+    source: exporters/otlp.yml
+    name: upstream
+    vars:
+      authenticator: $components.auth
+      endpoint: $args.endpoint
+  debug:
+    source: exporters/debug.yml
+service:
+  extensions:
+  - $components.auth
+  pipelines:
+    traces:
+      receivers:
+      - $components.otlp-receiver
+      exporters:
+      - $components.otlp-exporter
+      - $components.debug
+    logs/debug:
+      receivers:
+      - $components.otlp-receiver
+      exporters:
+      - $components.debug
+`
+
+func TestRecipeBuilder(t *testing.T) {
+	builder := NewRecipeBuilder("Receives OTLP data and exports it to an OTLP endpoint.\n")
+	builder.DisableLintRules("no-debug-exporter")
+	endpoint := builder.AddArg("endpoint", Arg{Description: "The OTLP endpoint", Env: "OTLP_ENDPOINT", Default: "localhost:4317"})
+	token := builder.AddArg("token", Arg{Description: "The OTLP token", Secret: true})
+	port := builder.AddConst("port", 4318)
+	receiver := builder.AddComponent("otlp-receiver", RecipeComponent{
+		Source:         "receivers/otlp.yml",
+		Configurations: []string{"http"},
+		Vars:           map[string]any{"port": port},
+	})
+	auth := builder.AddComponent("auth", RecipeComponent{
+		Source: "extensions/auth.yml",
+		Vars:   map[string]any{"token": token},
+	})
+	exporter := builder.AddComponent("otlp-exporter", RecipeComponent{
+		Source: "exporters/otlp.yml",
+		Name:   "upstream",
+		Vars:   map[string]any{"endpoint": endpoint, "authenticator": auth.Reference()},
+	})
+	debug := builder.AddComponent("debug", RecipeComponent{Source: "exporters/debug.yml"})
+	builder.AddExtension(auth)
+	builder.AddPipeline("traces", Pipeline{
+		Receivers: []ComponentHandle{receiver},
+		Exporters: []ComponentHandle{exporter, debug},
+	})
+	builder.AddPipeline("logs/debug", Pipeline{
+		Receivers: []ComponentHandle{receiver},
+		Exporters: []ComponentHandle{debug},
+	})
+
+	recipe, err := builder.Build()
+	assert.NoError(t, err)
+	parsed, err := ParseRecipe(strings.NewReader(builtRecipeYaml))
+	assert.NoError(t, err)
+	assert.Equal(t, parsed, recipe)
+
+	data, err := MarshalRecipe(&recipe)
+	assert.NoError(t, err)
+	assert.Equal(t, builtRecipeYaml, string(data))
+
+	componentsDir := writeFiles(t, map[string]string{
+		"receivers/otlp.yml": `
+vars:
+  port:
+    required: true
+configurations:
+  http:
+    content:
+      protocols:
+        http:
+          endpoint: localhost:$vars.port
+`,
+		"extensions/auth.yml": `
+vars:
+  token:
+    required: true
+configurations:
+  default:
+    content:
+      token: $vars.token
+`,
+		"exporters/otlp.yml": `
+vars:
+  endpoint:
+    required: true
+  authenticator:
+    required: true
+configurations:
+  default:
+    content:
+      endpoint: $vars.endpoint
+      auth:
+        authenticator: $vars.authenticator
+`,
+		"exporters/debug.yml": `
+configurations:
+  default:
+    content: {}
+`,
+	})
+	configuration, err := BuildRecipe(&recipe, BuildOptions{
+		Args:       map[string]string{"token": "secret"},
+		Components: os.DirFS(componentsDir),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"endpoint": "localhost:4317",
+		"auth":     map[string]any{"authenticator": "auth"},
+	}, configuration["exporters"].(map[string]any)["otlp/upstream"])
+}
+
+func TestRecipeBuilderErrors(t *testing.T) {
+	builder := NewRecipeBuilder("Invalid recipe")
+	builder.AddArg("endpoint", Arg{Description: "The endpoint"})
+	builder.AddArg("endpoint", Arg{Description: "The endpoint, again"})
+	builder.AddConst("port", 4317)
+	builder.AddConst("port", 4318)
+	receiver := builder.AddComponent("otlp", RecipeComponent{Source: "receivers/otlp.yml"})
+	builder.AddComponent("otlp", RecipeComponent{Source: "receivers/otlp.yml"})
+	other := NewRecipeBuilder("Other recipe").AddComponent("debug", RecipeComponent{Source: "exporters/debug.yml"})
+	builder.AddPipeline("traces", Pipeline{Receivers: []ComponentHandle{receiver}, Exporters: []ComponentHandle{other}})
+	builder.AddPipeline("traces", Pipeline{Receivers: []ComponentHandle{receiver}})
+
+	_, err := builder.Build()
+	assert.EqualError(t, err, `arg 'endpoint' is already declared
+const 'port' is already declared
+component 'otlp' is already declared
+component 'debug' isn't declared in this recipe
+pipeline 'traces' is already declared`)
+
+	builder = NewRecipeBuilder("")
+	_, err = builder.Build()
+	assert.ErrorContains(t, err, "Description")
+}
+
+func TestMarshalRecipeRoundTrip(t *testing.T) {
+	recipes := os.DirFS("../../../recipes")
+	err := fs.WalkDir(recipes, ".", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && strings.HasSuffix(d.Name(), recipeTestsDirSuffix) {
+			return fs.SkipDir
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		recipe, err := LoadRecipe(recipes, path)
+		assert.NoError(t, err)
+		data, err := MarshalRecipe(&recipe)
+		assert.NoError(t, err)
+		parsed, err := ParseRecipe(strings.NewReader(string(data)))
+		assert.NoError(t, err)
+		assert.Equal(t, recipe, parsed, path)
+		return nil
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,94 @@
+package configurator
+
+import (
+	"io/fs"
+	"slices"
+	"sync"
+)
+
+// ComponentLoader loads the component file at the given path. Building a component modifies it, so every call must
+// return a component of its own.
+type ComponentLoader interface {
+	Load(path string) (*Component, error)
+}
+
+// ComponentCache parses each component file of a file system once, so that several builds, or several recipe
+// components sharing a source, share the parsed component. Building a component modifies it, so the parsed components
+// are never handed out: Load returns deep copies of them. It's safe for concurrent use.
+type ComponentCache struct {
+	fsys    fs.FS
+	mu      sync.Mutex
+	entries map[string]*componentCacheEntry
+}
+
+type componentCacheEntry struct {
+	once      sync.Once
+	component *Component
+	err       error
+}
+
+func NewComponentCache(fsys fs.FS) *ComponentCache {
+	return &ComponentCache{fsys: fsys, entries: make(map[string]*componentCacheEntry)}
+}
+
+// Load returns a copy of the component file at the given path, parsing it on the first call.
+func (c *ComponentCache) Load(path string) (*Component, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[path]
+	if !ok {
+		entry = &componentCacheEntry{}
+		c.entries[path] = entry
+	}
+	c.mu.Unlock()
+	entry.once.Do(func() {
+		entry.component, entry.err = LoadComponent(c.fsys, path)
+	})
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	return entry.component.clone(), nil
+}
+
+func (c *Component) clone() *Component {
+	cp := *c
+	cp.Metadata.Signals = slices.Clone(c.Metadata.Signals)
+	cp.Configurations = cloneMap(c.Configurations, Configuration.clone)
+	cp.Vars = cloneMap(c.Vars, func(decl VarDecl) VarDecl {
+		decl.Default = deepCopyAny(decl.Default)
+		return decl
+	})
+	cp.Refs = cloneMap(c.Refs, deepCopyAny)
+	cp.Schema = deepCopyAny(c.Schema)
+	cp.Tests = slices.Clone(c.Tests)
+	for i, test := range cp.Tests {
+		cp.Tests[i].Configurations = slices.Clone(test.Configurations)
+		cp.Tests[i].Vars = cloneMap(test.Vars, deepCopyAny)
+		cp.Tests[i].Expected = deepCopyAny(test.Expected)
+	}
+	return &cp
+}
+
+func (c Configuration) clone() Configuration {
+	cp := c
+	cp.Extends = slices.Clone(c.Extends)
+	cp.Content = deepCopyAny(c.Content)
+	cp.Vars = cloneMap(c.Vars, deepCopyAny)
+	cp.Refs = cloneMap(c.Refs, deepCopyAny)
+	cp.Append = slices.Clone(c.Append)
+	for i, item := range cp.Append {
+		cp.Append[i].Content = deepCopyAny(item.Content)
+	}
+	return cp
+}
+
+// cloneMap copies m, cloning its values with clone, and keeps nil maps nil.
+func cloneMap[M ~map[string]V, V any](m M, clone func(V) V) M {
+	if m == nil {
+		return nil
+	}
+	cp := make(M, len(m))
+	for k, v := range m {
+		cp[k] = clone(v)
+	}
+	return cp
+}
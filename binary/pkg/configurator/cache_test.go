@@ -0,0 +1,191 @@
+package configurator
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingFS struct {
+	fs.FS
+	mu     sync.Mutex
+	opened map[string]int
+}
+
+func (c *countingFS) Open(name string) (fs.File, error) {
+	c.mu.Lock()
+	c.opened[name]++
+	c.mu.Unlock()
+	return c.FS.Open(name)
+}
+
+func TestComponentCache(t *testing.T) {
+	componentsFS := &countingFS{FS: fstest.MapFS{
+		"receivers/otlp.yml": {Data: []byte(dummyComponent)},
+	}, opened: make(map[string]int)}
+	cache := NewComponentCache(componentsFS)
+
+	first, err := cache.Load("receivers/otlp.yml")
+	assert.NoError(t, err)
+	_, err = buildParsedComponent(first, ComponentParams{Name: "otlp", ConfigurationNames: []string{"someconfig"}, Vars: map[string]any{
+		"some_var":            "value",
+		"some_component_name": "name",
+	}})
+	assert.NoError(t, err)
+	first.Refs["base"].(map[string]any)["es_endpoint"] = "modified"
+
+	second, err := cache.Load("receivers/otlp.yml")
+	assert.NoError(t, err)
+	expected, err := LoadComponent(componentsFS, "receivers/otlp.yml")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, second)
+	assert.NotEqual(t, first, second)
+
+	_, err = cache.Load("receivers/missing.yml")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = cache.Load("receivers/missing.yml")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.Equal(t, map[string]int{"receivers/otlp.yml": 2, "receivers/missing.yml": 1}, componentsFS.opened)
+}
+
+func TestComponentCloneIsDeep(t *testing.T) {
+	component, err := ParseComponent(strings.NewReader(`
+metadata:
+  signals: [ traces ]
+vars:
+  endpoint:
+    default: { host: localhost }
+refs:
+  base:
+    endpoint: $vars.endpoint
+configurations:
+  default:
+    extends: [ base ]
+  base:
+    content:
+      nested: { list: [ { key: value } ] }
+    vars:
+      port: 4317
+    refs:
+      other: { key: value }
+    append:
+      - path: $.nested
+        content: { extra: value }
+schema:
+  type: object
+tests:
+  - name: default
+    configurations: [ default ]
+    vars: { endpoint: { host: remote } }
+    expected: { nested: { list: [ { key: value } ] } }
+`))
+	assert.NoError(t, err)
+	original := component.clone()
+	cp := component.clone()
+	assert.Equal(t, original, cp)
+
+	cp.Metadata.Signals[0] = "logs"
+	cp.Vars["endpoint"].Default.(map[string]any)["host"] = "modified"
+	cp.Refs["base"].(map[string]any)["endpoint"] = "modified"
+	cp.Configurations["default"].Extends[0] = "modified"
+	cp.Configurations["base"].Content.(map[string]any)["nested"].(map[string]any)["list"].([]any)[0].(map[string]any)["key"] = "modified"
+	cp.Configurations["base"].Vars["port"] = 4318
+	cp.Configurations["base"].Refs["other"].(map[string]any)["key"] = "modified"
+	cp.Configurations["base"].Append[0].Content.(map[string]any)["extra"] = "modified"
+	cp.Schema.(map[string]any)["type"] = "modified"
+	cp.Tests[0].Configurations[0] = "modified"
+	cp.Tests[0].Vars["endpoint"].(map[string]any)["host"] = "modified"
+	cp.Tests[0].Expected.(map[string]any)["nested"] = "modified"
+	assert.Equal(t, original, component)
+}
+
+func TestBuildRecipeParsesSharedSourcesOnce(t *testing.T) {
+	componentsFS := &countingFS{FS: fstest.MapFS{
+		"dummypath/dummy.yml":         {Data: []byte(dummyComponent)},
+		"dummypath/dummyreceiver.yml": {Data: []byte(dummyReceiverComponent)},
+	}, opened: make(map[string]int)}
+	recipe, err := ParseRecipe(strings.NewReader(dummyRecipe))
+	assert.NoError(t, err)
+
+	_, err = BuildRecipe(&recipe, BuildOptions{
+		Args:       map[string]string{"endpoint": "http://localhost:9200", "api_key": "key"},
+		Components: componentsFS,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"dummypath/dummy.yml": 1, "dummypath/dummyreceiver.yml": 1}, componentsFS.opened)
+}
+
+func largeRecipe(components int) string {
+	var recipe strings.Builder
+	recipe.WriteString("description: Large recipe\nargs: {}\ncomponents:\n")
+	for i := range components {
+		fmt.Fprintf(&recipe, "  exporter-%d:\n    source: dummypath/dummy.yml\n    name: exporter-%d\n", i, i)
+		fmt.Fprintf(&recipe, "    configurations: [ someconfig ]\n    vars:\n      some_var: value-%d\n      some_component_name: name-%d\n", i, i)
+	}
+	recipe.WriteString("  otlp:\n    source: receivers/otlp.yml\nservice:\n  pipelines:\n    traces:\n      receivers: [ $components.otlp ]\n      exporters:\n")
+	for i := range components {
+		fmt.Fprintf(&recipe, "        - $components.exporter-%d\n", i)
+	}
+	return recipe.String()
+}
+
+func BenchmarkComponentLoading(b *testing.B) {
+	componentsFS := fstest.MapFS{"dummypath/dummy.yml": {Data: []byte(dummyComponent)}}
+	b.Run("parse", func(b *testing.B) {
+		for b.Loop() {
+			_, err := LoadComponent(componentsFS, "dummypath/dummy.yml")
+			assert.NoError(b, err)
+		}
+	})
+	b.Run("cache", func(b *testing.B) {
+		cache := NewComponentCache(componentsFS)
+		for b.Loop() {
+			_, err := cache.Load("dummypath/dummy.yml")
+			assert.NoError(b, err)
+		}
+	})
+}
+
+// componentParser parses the component file on every load, as builds did before ComponentCache.
+type componentParser struct {
+	fsys fs.FS
+}
+
+func (p componentParser) Load(path string) (*Component, error) {
+	return LoadComponent(p.fsys, path)
+}
+
+func BenchmarkBuildLargeRecipe(b *testing.B) {
+	componentsFS := fstest.MapFS{
+		"dummypath/dummy.yml": {Data: []byte(dummyComponent)},
+		"receivers/otlp.yml":  {Data: []byte("configurations:\n  default:\n    content: {}\n")},
+	}
+	for _, size := range []int{10, 100} {
+		recipe, err := ParseRecipe(strings.NewReader(largeRecipe(size)))
+		assert.NoError(b, err)
+		b.Run(fmt.Sprintf("%d components/parse per entry", size), func(b *testing.B) {
+			for b.Loop() {
+				_, err := BuildRecipe(&recipe, BuildOptions{Components: componentsFS, ComponentCache: componentParser{componentsFS}})
+				assert.NoError(b, err)
+			}
+		})
+		b.Run(fmt.Sprintf("%d components/cache per build", size), func(b *testing.B) {
+			for b.Loop() {
+				_, err := BuildRecipe(&recipe, BuildOptions{Components: componentsFS})
+				assert.NoError(b, err)
+			}
+		})
+		b.Run(fmt.Sprintf("%d components/shared cache", size), func(b *testing.B) {
+			cache := NewComponentCache(componentsFS)
+			for b.Loop() {
+				_, err := BuildRecipe(&recipe, BuildOptions{Components: componentsFS, ComponentCache: cache})
+				assert.NoError(b, err)
+			}
+		})
+	}
+}
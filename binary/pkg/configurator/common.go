@@ -0,0 +1,385 @@
+package configurator
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/goccy/go-yaml"
+)
+
+type Vars map[string]any
+
+// StringList is a list of strings that can also be written as a single string in YAML.
+type StringList []string
+
+func (l *StringList) UnmarshalYAML(unmarshal func(any) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*l = StringList{single}
+		return nil
+	}
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+func isPrimitive(value any) bool {
+	kindName := getKind(value).String()
+	for _, primitiveName := range []string{
+		"string",
+		"bool",
+		"int",
+		"uint",
+		"float",
+	} {
+		if strings.HasPrefix(kindName, primitiveName) {
+			return true
+		}
+	}
+	return false
+}
+
+func isString(value any) bool {
+	return getKind(value) == reflect.String
+}
+
+func isBool(value any) bool {
+	return getKind(value) == reflect.Bool
+}
+
+func isInteger(value any) bool {
+	kindName := getKind(value).String()
+	return strings.HasPrefix(kindName, "int") || strings.HasPrefix(kindName, "uint")
+}
+
+func isFloat(value any) bool {
+	return strings.HasPrefix(getKind(value).String(), "float")
+}
+
+func isMap(value any) bool {
+	return getKind(value) == reflect.Map
+}
+
+func isList(value any) bool {
+	return isSlice(value) || isArray(value)
+}
+
+func isSlice(value any) bool {
+	return getKind(value) == reflect.Slice
+}
+
+func isArray(value any) bool {
+	return getKind(value) == reflect.Array
+}
+
+func getKind(value any) reflect.Kind {
+	if value == nil {
+		return reflect.Invalid
+	}
+	return reflect.TypeOf(value).Kind()
+}
+
+func deepCopy[T any](v T) T {
+	return deepCopyAny(v).(T)
+}
+
+func deepCopyAny(value any) any {
+	switch {
+	case isMap(value):
+		mapValue := value.(map[string]any)
+		cp := make(map[string]any, len(mapValue))
+		for k, v2 := range mapValue {
+			cp[k] = deepCopyAny(v2)
+		}
+		return cp
+
+	case isSlice(value):
+		sliceValue := value.([]any)
+		cp := make([]any, len(sliceValue))
+		for i, v2 := range sliceValue {
+			cp[i] = deepCopyAny(v2)
+		}
+		return cp
+
+	default:
+		return value
+	}
+}
+
+// MergeStrategy sets how conflicting keys and lists are merged.
+type MergeStrategy string
+
+const (
+	MergeStrategyAppend       MergeStrategy = "append"
+	MergeStrategyAppendUnique MergeStrategy = "append-unique"
+	MergeStrategyReplace      MergeStrategy = "replace"
+	MergeStrategyError        MergeStrategy = "error"
+)
+
+type mergeOptions struct {
+	Strategy  MergeStrategy
+	DstSource string
+	SrcSource string
+	Origins   map[string]string
+}
+
+type MergeConflictError struct {
+	Path      string
+	DstSource string
+	SrcSource string
+	Reason    string
+}
+
+func (e *MergeConflictError) Error() string {
+	if e.DstSource == "" && e.SrcSource == "" {
+		return fmt.Sprintf("merge conflict at '%s': %s", e.Path, e.Reason)
+	}
+	return fmt.Sprintf("merge conflict at '%s' between %s and %s: %s", e.Path, e.DstSource, e.SrcSource, e.Reason)
+}
+
+func mergeMaps(dst map[string]any, src map[string]any, opts mergeOptions) error {
+	return mergeMapsAt(dst, src, []string{}, opts)
+}
+
+func mergeMapsAt(dst map[string]any, src map[string]any, path []string, opts mergeOptions) error {
+	for _, k := range slices.Sorted(maps.Keys(src)) {
+		v := src[k]
+		keyPath := append(slices.Clone(path), k)
+		dstVal, found := dst[k]
+		if !found {
+			dst[k] = v
+			opts.recordOrigin(keyPath)
+			continue
+		}
+		switch {
+		case isMap(v) && isMap(dstVal):
+			err := mergeMapsAt(dstVal.(map[string]any), v.(map[string]any), keyPath, opts)
+			if err != nil {
+				return err
+			}
+		case isList(v) && isList(dstVal):
+			merged, err := mergeLists(dstVal.([]any), v.([]any), keyPath, opts)
+			if err != nil {
+				return err
+			}
+			dst[k] = merged
+		case opts.strategy() == MergeStrategyReplace:
+			dst[k] = v
+			opts.recordOrigin(keyPath)
+		case isMap(v) != isMap(dstVal) || isList(v) != isList(dstVal):
+			return opts.conflict(keyPath, fmt.Sprintf("type mismatch, cannot merge %v into %v", getKind(v), getKind(dstVal)))
+		case opts.strategy() == MergeStrategyAppendUnique && reflect.DeepEqual(dstVal, v):
+			continue
+		default:
+			return opts.conflict(keyPath, "key overlap")
+		}
+	}
+	return nil
+}
+
+func mergeLists(dst []any, src []any, path []string, opts mergeOptions) ([]any, error) {
+	switch opts.strategy() {
+	case MergeStrategyReplace:
+		opts.recordOrigin(path)
+		return slices.Clone(src), nil
+	case MergeStrategyError:
+		return nil, opts.conflict(path, "list overlap")
+	case MergeStrategyAppendUnique:
+		merged := slices.Clone(dst)
+		for _, item := range src {
+			if !slices.ContainsFunc(merged, func(existing any) bool {
+				return reflect.DeepEqual(existing, item)
+			}) {
+				merged = append(merged, item)
+			}
+		}
+		return merged, nil
+	default:
+		return append(slices.Clone(dst), src...), nil
+	}
+}
+
+func (o mergeOptions) strategy() MergeStrategy {
+	if o.Strategy == "" {
+		return MergeStrategyAppend
+	}
+	return o.Strategy
+}
+
+func (o mergeOptions) recordOrigin(path []string) {
+	if o.Origins != nil && o.SrcSource != "" {
+		o.Origins[JoinPath(path)] = o.SrcSource
+	}
+}
+
+func (o mergeOptions) conflict(path []string, reason string) error {
+	dstSource := o.DstSource
+	for i := len(path); i > 0; i-- {
+		origin, ok := o.Origins[JoinPath(path[:i])]
+		if ok {
+			dstSource = origin
+			break
+		}
+	}
+	return &MergeConflictError{
+		Path:      JoinPath(path),
+		DstSource: dstSource,
+		SrcSource: o.SrcSource,
+		Reason:    reason,
+	}
+}
+
+func JoinPath(path []string) string {
+	var joined strings.Builder
+	for i, item := range path {
+		if strings.HasPrefix(item, "[") {
+			joined.WriteString(item)
+			continue
+		}
+		if i > 0 {
+			joined.WriteString(".")
+		}
+		if strings.Contains(item, ".") {
+			item = fmt.Sprintf("'%s'", item)
+		}
+		joined.WriteString(item)
+	}
+	return joined.String()
+}
+
+type placeholderTracer func(path []string, text string, placeholder string, value any)
+
+func replacePlaceholdersInMap(target map[string]any, placeholderPattern regexp.Regexp, values map[string]any) error {
+	return replacePlaceholdersInMapAt(target, []string{}, placeholderPattern, values, nil)
+}
+
+func replacePlaceholdersInMapAt(target map[string]any, path []string, placeholderPattern regexp.Regexp, values map[string]any, tracer placeholderTracer) error {
+	for k, v := range target {
+		keyPath := append(slices.Clone(path), k)
+		if isMap(v) {
+			err := replacePlaceholdersInMapAt(v.(map[string]any), keyPath, placeholderPattern, values, tracer)
+			if err != nil {
+				return err
+			}
+		} else if isList(v) {
+			list, err := replacePlaceholdersInListAt(v.([]any), keyPath, placeholderPattern, values, tracer)
+			if err != nil {
+				return err
+			}
+			target[k] = list
+		} else if isString(v) {
+			resolvedValue, err := resolvePlaceholdersInStringAt(v.(string), keyPath, placeholderPattern, values, tracer)
+			if err != nil {
+				return err
+			}
+			target[k] = resolvedValue
+		}
+	}
+	return nil
+}
+
+func replacePlaceholdersInList(list []any, placeholderPattern regexp.Regexp, values map[string]any) ([]any, error) {
+	return replacePlaceholdersInListAt(list, []string{}, placeholderPattern, values, nil)
+}
+
+func replacePlaceholdersInListAt(list []any, path []string, placeholderPattern regexp.Regexp, values map[string]any, tracer placeholderTracer) ([]any, error) {
+	resolvedList := make([]any, len(list))
+	for i, v := range list {
+		itemPath := append(slices.Clone(path), fmt.Sprintf("[%d]", i))
+		if isMap(v) {
+			err := replacePlaceholdersInMapAt(v.(map[string]any), itemPath, placeholderPattern, values, tracer)
+			if err != nil {
+				return nil, err
+			}
+			resolvedList[i] = v
+		} else if isString(v) {
+			resolvedValue, err := resolvePlaceholdersInStringAt(v.(string), itemPath, placeholderPattern, values, tracer)
+			if err != nil {
+				return nil, err
+			}
+			resolvedList[i] = resolvedValue
+		} else {
+			resolvedList[i] = v
+		}
+	}
+	return resolvedList, nil
+}
+
+func resolvePlaceholdersInStringAt(target string, path []string, placeholderPattern regexp.Regexp, values map[string]any, tracer placeholderTracer) (any, error) {
+	var fullTextPatterns []string
+	for _, pattern := range strings.Split(placeholderPattern.String(), "|") {
+		fullTextPatterns = append(fullTextPatterns, fmt.Sprintf("^%s$", pattern))
+	}
+	fullTextPattern := regexp.MustCompile(strings.Join(fullTextPatterns, "|"))
+	if fullTextPattern.MatchString(target) {
+		mapValue, ok := values[target]
+		if ok {
+			if tracer != nil {
+				tracer(path, target, target, mapValue)
+			}
+			return mapValue, nil
+		} else {
+			return nil, fmt.Errorf("'%s' is not defined, the available values are: %v", target, values)
+		}
+	} else if placeholderPattern.MatchString(target) {
+		matches := placeholderPattern.FindAllString(target, -1)
+		if len(matches) > 0 {
+			newValue := target
+			for _, v := range slices.Compact(matches) {
+				mapValue, ok := values[v]
+				if ok {
+					newValue = strings.ReplaceAll(newValue, v, fmt.Sprintf("%v", mapValue))
+					if tracer != nil {
+						tracer(path, target, v, mapValue)
+					}
+				} else {
+					return nil, fmt.Errorf("'%s' (within the value '%s') is not defined, the available values are: %v", v, target, values)
+				}
+			}
+			return newValue, nil
+		}
+	}
+	return target, nil
+}
+
+func prependToKeysOfPrimitiveValues[V any](target map[string]V, prefix string) (map[string]V, error) {
+	refPrefixedMap := make(map[string]V, len(target))
+	var keyName string
+	for k, v := range target {
+		keyName = prefix + k
+		if !isPrimitive(v) {
+			return nil, fmt.Errorf("'%s' format is not valid, only primitives are allowed", keyName)
+		}
+		refPrefixedMap[keyName] = v
+	}
+	return refPrefixedMap, nil
+}
+
+func parseYamlFile(data io.Reader, result any) error {
+	validate := validator.New()
+	err := validate.RegisterValidation("version", func(fl validator.FieldLevel) bool {
+		return versionPattern.MatchString(fl.Field().String())
+	})
+	if err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(
+		data,
+		yaml.Validator(validate),
+		yaml.Strict(),
+	)
+	err = dec.Decode(result)
+	if err != nil {
+		return err
+	}
+	return nil
+}
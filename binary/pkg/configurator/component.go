@@ -0,0 +1,453 @@
+package configurator
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+type ComponentParams struct {
+	Name               string
+	ConfigurationNames []string
+	Vars               map[string]any
+	VarsScope          string
+	Trace              func(Resolution)
+}
+
+type Resolution struct {
+	Path          []string
+	Text          string
+	Placeholder   string
+	Value         any
+	Scope         string
+	Source        string
+	Configuration string
+	Via           []Resolution
+}
+
+type Refs map[string]any
+
+// Append adds content at a path of a configuration's content.
+type Append struct {
+	Path    string        `validate:"required"`
+	Content any           `validate:"required"`
+	Merge   MergeStrategy `validate:"omitempty,oneof=append append-unique replace error"`
+}
+
+type Configuration struct {
+	Extends StringList
+	Content any `validate:"required_without=Extends"`
+	Vars    Vars
+	Refs    Refs
+	Append  []Append
+	Merge   MergeStrategy `validate:"omitempty,oneof=append append-unique replace error"`
+}
+
+type Component struct {
+	Metadata       Metadata
+	Configurations map[string]Configuration `validate:"required"`
+	Vars           VarDecls
+	Refs           Refs
+	Schema         any
+	Tests          []ComponentTest `validate:"dive"`
+}
+
+var (
+	varsPattern         = regexp.MustCompile(`\$vars\.[^\s]+`)
+	refsPattern         = regexp.MustCompile(`^\$refs\.[^\s]+$`)
+	yamlPathPattern     = regexp.MustCompile(`^\$((?:\.[^\s.]+)+)?$`)
+	dotSeparatedPattern = regexp.MustCompile(`'[^\s]+'|[^.\s]+`)
+)
+
+func ParseComponent(source io.Reader) (*Component, error) {
+	component := &Component{}
+	err := parseYamlFile(source, component)
+	if err != nil {
+		return nil, err
+	}
+	return component, nil
+}
+
+func BuildComponent(source io.Reader, params ComponentParams) (map[string]any, error) {
+	component, err := ParseComponent(source)
+	if err != nil {
+		return nil, err
+	}
+	return buildParsedComponent(component, params)
+}
+
+func buildParsedComponent(component *Component, params ComponentParams) (map[string]any, error) {
+	var err error
+	if params.Name == "" {
+		return nil, fmt.Errorf("name param not set")
+	}
+	err = component.Vars.checkProvided(component, params.Vars)
+	if err != nil {
+		return nil, err
+	}
+	body := make(map[string]any)
+	origins := make(map[string]string)
+	var configs = params.ConfigurationNames
+	if len(configs) == 0 {
+		configs = []string{"default"}
+	}
+	for _, key := range configs {
+		configuration, err := resolveConfiguration(component, key, []string{})
+		if err != nil {
+			return nil, err
+		}
+		configVars, varScopes, err := collectVars(component, key, configuration, params)
+		if err != nil {
+			return nil, err
+		}
+		resolve := params.varsResolver(configVars, varScopes, key)
+		configRefs := collectRefs(component.Refs, configuration)
+		configContent, err := resolveConfigContent(configuration.Content, configRefs)
+		if err != nil {
+			return nil, err
+		}
+		_, err = resolve(configContent, []string{}, 0)
+		if err != nil {
+			return nil, err
+		}
+		traceOrigins(params.Trace, configContent, []string{}, key, "")
+		err = mergeMaps(body, configContent, mergeOptions{
+			Strategy:  configuration.Merge,
+			SrcSource: fmt.Sprintf("configuration '%s'", key),
+			Origins:   origins,
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = appendItems(body, configuration.Append, resolve)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range configuration.Append {
+			path, _ := parseYamlPath(item.Path)
+			if isMap(item.Content) {
+				traceOrigins(params.Trace, item.Content, path, key, "")
+			} else {
+				traceOrigins(params.Trace, nil, path, key, "")
+			}
+		}
+	}
+
+	return map[string]any{
+		params.Name: body,
+	}, nil
+}
+
+func resolveConfiguration(component *Component, name string, chain []string) (Configuration, error) {
+	configuration, ok := component.Configurations[name]
+	if !ok {
+		return Configuration{}, fmt.Errorf("couldn't find configuration named '%v'", name)
+	}
+	if slices.Contains(chain, name) {
+		return Configuration{}, fmt.Errorf("configuration inheritance cycle: %s", strings.Join(append(chain, name), " -> "))
+	}
+	chain = append(slices.Clone(chain), name)
+	resolved := Configuration{
+		Vars: make(Vars),
+		Refs: make(Refs),
+	}
+	var contentParents []string
+	for _, parentName := range configuration.Extends {
+		if _, ok := component.Configurations[parentName]; !ok {
+			return Configuration{}, fmt.Errorf("configuration '%s' extends an unknown configuration: '%s'", name, parentName)
+		}
+		parent, err := resolveConfiguration(component, parentName, chain)
+		if err != nil {
+			return Configuration{}, err
+		}
+		if parent.Content != nil {
+			resolved.Content = parent.Content
+			contentParents = append(contentParents, parentName)
+		}
+		if parent.Merge != "" {
+			resolved.Merge = parent.Merge
+		}
+		maps.Copy(resolved.Vars, parent.Vars)
+		maps.Copy(resolved.Refs, parent.Refs)
+		resolved.Append = append(resolved.Append, parent.Append...)
+	}
+	if configuration.Content != nil {
+		resolved.Content = configuration.Content
+	} else if len(contentParents) > 1 {
+		return Configuration{}, fmt.Errorf("configuration '%s' inherits content from several configurations: '%s', only one parent can provide it unless the configuration defines its own", name, strings.Join(contentParents, "', '"))
+	}
+	if configuration.Merge != "" {
+		resolved.Merge = configuration.Merge
+	}
+	maps.Copy(resolved.Vars, configuration.Vars)
+	maps.Copy(resolved.Refs, configuration.Refs)
+	resolved.Append = append(resolved.Append, configuration.Append...)
+
+	resolved.Content = deepCopyAny(resolved.Content)
+	for i, item := range resolved.Append {
+		item.Content = deepCopyAny(item.Content)
+		resolved.Append[i] = item
+	}
+	return resolved, nil
+}
+
+type contentResolver func(content any, path []string, offset int) (any, error)
+
+func (p ComponentParams) varsResolver(vars Vars, scopes map[string]string, configuration string) contentResolver {
+	return func(content any, path []string, offset int) (any, error) {
+		tracer := p.tracer(scopes, configuration, len(path), offset)
+		if isMap(content) {
+			err := replacePlaceholdersInMapAt(content.(map[string]any), path, *varsPattern, vars, tracer)
+			return content, err
+		} else if isList(content) {
+			return replacePlaceholdersInListAt(content.([]any), path, *varsPattern, vars, tracer)
+		}
+		return content, nil
+	}
+}
+
+func (p ComponentParams) tracer(scopes map[string]string, configuration string, listIndexPosition int, offset int) placeholderTracer {
+	if p.Trace == nil {
+		return nil
+	}
+	return func(path []string, text string, placeholder string, value any) {
+		path = slices.Clone(path)
+		if offset > 0 && len(path) > listIndexPosition {
+			var index int
+			fmt.Sscanf(path[listIndexPosition], "[%d]", &index)
+			path[listIndexPosition] = fmt.Sprintf("[%d]", index+offset)
+		}
+		p.Trace(Resolution{
+			Path:          path,
+			Text:          text,
+			Placeholder:   placeholder,
+			Value:         value,
+			Scope:         scopes[placeholder],
+			Configuration: configuration,
+		})
+	}
+}
+
+func traceOrigins(trace func(Resolution), content any, path []string, configuration string, source string) {
+	if trace == nil {
+		return
+	}
+	walkValues(content, path, func(path []string, value any) {
+		trace(Resolution{
+			Path:          path,
+			Value:         value,
+			Source:        source,
+			Configuration: configuration,
+		})
+	})
+}
+
+func (p ComponentParams) varsScope() string {
+	if p.VarsScope == "" {
+		return "provided vars"
+	}
+	return p.VarsScope
+}
+
+func appendItems(body map[string]any, items []Append, resolve contentResolver) error {
+	var err error
+	for _, item := range items {
+		err = appendItem(body, item, resolve)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendItem(body map[string]any, item Append, resolve contentResolver) error {
+	var err error
+	path, err := parseYamlPath(item.Path)
+	if err != nil {
+		return err
+	}
+	if isMap(item.Content) {
+		content, err := resolve(item.Content, path, 0)
+		if err != nil {
+			return err
+		}
+		err = appendMapItems(body, path, content.(map[string]any), item.Merge)
+		if err != nil {
+			return err
+		}
+	} else if isList(item.Content) {
+		err = appendListItems(body, path, item.Content.([]any), item.Merge, resolve)
+		if err != nil {
+			return err
+		}
+	} else {
+		return fmt.Errorf("invalid append content type, must be a map or list - it's: %v", getKind(item.Content))
+	}
+	return nil
+}
+
+func appendMapItems(body map[string]any, path []string, content map[string]any, strategy MergeStrategy) error {
+	var targetMap map[string]any = body
+	var ok bool
+	for _, pathItem := range path {
+		targetMap, ok = targetMap[pathItem].(map[string]any)
+		if !ok {
+			return fmt.Errorf("could not find item '%s' via yaml path: %v", pathItem, path)
+		}
+	}
+	if strategy != "" {
+		return mergeMapsAt(targetMap, content, path, mergeOptions{Strategy: strategy})
+	}
+	for k, v := range content {
+		if targetMap[k] != nil {
+			return fmt.Errorf("key '%s' already exists in target map, cannot append existing keys", k)
+		}
+		targetMap[k] = v
+	}
+	return nil
+}
+
+func appendListItems(body map[string]any, path []string, content []any, strategy MergeStrategy, resolve contentResolver) error {
+	if len(path) == 0 {
+		return fmt.Errorf("could not append a list to the root of the configuration, the yaml path must point to a list")
+	}
+	var targetMap map[string]any = body
+	var pathToMap = path[:len(path)-1]
+	var ok bool
+	for _, pathItem := range pathToMap {
+		targetMap, ok = targetMap[pathItem].(map[string]any)
+		if !ok {
+			return fmt.Errorf("could not find item '%s' via yaml path: %v", pathItem, path)
+		}
+	}
+	listKey := path[len(path)-1]
+	originalList, ok := targetMap[listKey].([]any)
+	if !ok {
+		return fmt.Errorf("could not find list '%s' via yaml path: %v", listKey, path)
+	}
+	resolvedContent, err := resolve(content, path, len(originalList))
+	if err != nil {
+		return err
+	}
+	merged, err := mergeLists(originalList, resolvedContent.([]any), path, mergeOptions{Strategy: strategy})
+	if err != nil {
+		return err
+	}
+	targetMap[listKey] = merged
+
+	return nil
+}
+
+func resolveConfigContent(content any, configRefs Refs) (map[string]any, error) {
+	if isMap(content) {
+		err := resolveMapRefs(content.(map[string]any), configRefs)
+		if err != nil {
+			return nil, err
+		}
+		return content.(map[string]any), nil
+	} else if isString(content) {
+		mapRef, err := resolveStringRef(content.(string), configRefs)
+		if err != nil {
+			return nil, err
+		}
+		err = resolveMapRefs(mapRef, configRefs)
+		if err != nil {
+			return nil, err
+		}
+		return mapRef, nil
+	}
+	return nil, fmt.Errorf("invalid content type, must be a map or a ref to a map - it's: %v", getKind(content))
+}
+
+func resolveMapRefs(content map[string]any, configRefs Refs) error {
+	for k, v := range content {
+		if isString(v) && refsPattern.MatchString(v.(string)) {
+			mapRef, err := resolveStringRef(v.(string), configRefs)
+			if err != nil {
+				return err
+			}
+			err = resolveMapRefs(mapRef, configRefs)
+			if err != nil {
+				return err
+			}
+			content[k] = mapRef
+		}
+	}
+	return nil
+}
+
+func resolveStringRef(content string, configRefs Refs) (map[string]any, error) {
+	refId := refsPattern.FindString(content)
+	if refId == "" {
+		return nil, fmt.Errorf("'%v' is not a valid ref", content)
+	}
+	ref, ok := configRefs[refId]
+	if !ok {
+		return nil, fmt.Errorf("'%s' (within a component string '%s') is not defined, the available ones are: %v", refId, content, configRefs)
+	}
+	mapRef, ok := ref.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("'%s' (within a component string '%s') must be a map, it's: %v", refId, content, getKind(ref))
+	}
+	return mapRef, nil
+}
+
+func collectRefs(componentRefs Refs, configuration Configuration) Refs {
+	var collected = make(Refs)
+	if componentRefs != nil {
+		collected = deepCopy(map[string]any(componentRefs))
+	}
+	maps.Copy(collected, deepCopy(map[string]any(configuration.Refs)))
+	refPrefixedMap := make(Refs, len(collected))
+	for k, v := range collected {
+		refPrefixedMap["$refs."+k] = v
+	}
+
+	return refPrefixedMap
+}
+
+func collectVars(component *Component, configurationName string, configuration Configuration, params ComponentParams) (Vars, map[string]string, error) {
+	collected := make(Vars)
+	scopes := make(map[string]string)
+	for _, layer := range []struct {
+		vars  map[string]any
+		scope string
+	}{
+		{component.Vars.defaults(), "component vars"},
+		{configuration.Vars, fmt.Sprintf("configuration '%s' vars", configurationName)},
+		{params.Vars, params.varsScope()},
+	} {
+		for k, v := range layer.vars {
+			collected[k] = v
+			scopes["$vars."+k] = layer.scope
+		}
+	}
+	collected, err := component.Vars.checkValues(collected)
+	if err != nil {
+		return nil, nil, err
+	}
+	prefixed, err := prependToKeysOfPrimitiveValues(collected, "$vars.")
+	if err != nil {
+		return nil, nil, err
+	}
+	return prefixed, scopes, nil
+}
+
+func parseYamlPath(path string) ([]string, error) {
+	match := yamlPathPattern.FindAllStringSubmatch(path, -1)
+	if len(match) == 0 {
+		return nil, fmt.Errorf("invalid yaml path: %s", path)
+	}
+	subpath := match[0][1]
+	if subpath == "" {
+		return []string{}, nil
+	}
+	var items []string
+	for _, item := range dotSeparatedPattern.FindAllString(subpath, -1) {
+		items = append(items, strings.Trim(item, "'"))
+	}
+	return items, nil
+}
@@ -0,0 +1,853 @@
+package configurator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var simpleConfiguration = `
+configurations:
+  default:
+    content:
+      endpoint: default_endpoint
+      api_key: default_api_key
+  someconfig:
+    content:
+      endpoint: someconfig_endpoint
+      api_key: someconfig_api_key
+`
+
+var mergeableConfiguration = `
+configurations:
+  http:
+    content:
+      protocol:
+        http:
+          endpoint: http_endpoint
+  grpc:
+    content:
+      protocol:
+        grpc:
+          endpoint: grpc_endpoint
+`
+
+var unmergeableConfiguration = `
+configurations:
+  first:
+    content:
+      protocol:
+        http:
+          endpoint: first_http_endpoint
+  second:
+    content:
+      protocol:
+        http:
+          endpoint: second_http_endpoint
+`
+
+var configurationsWithLists = `
+configurations:
+  first:
+    content:
+      headers:
+        - accept
+        - user-agent
+      endpoint: first_endpoint
+  second:
+    content:
+      headers:
+        - user-agent
+        - authorization
+  unique:
+    merge: append-unique
+    content:
+      headers:
+        - user-agent
+        - authorization
+  replacing:
+    merge: replace
+    content:
+      headers:
+        - authorization
+      endpoint: replaced_endpoint
+  strict:
+    merge: error
+    content:
+      headers:
+        - authorization
+  mismatched:
+    content:
+      endpoint:
+        url: some_url
+  appending:
+    content: {}
+    append:
+      - path: "$.headers"
+        merge: append-unique
+        content:
+          - accept
+          - content-type
+`
+
+var configurationWithVars = `
+vars:
+  first: global_first
+  second: false
+  third: global_third
+  fourth: global_fourth
+configurations:
+  default:
+    content:
+      first_placeholder: $vars.first
+      second_placeholder: $vars.second
+      third_placeholder: $vars.third
+      some_list:
+        - The fourth is $vars.fourth
+      some_map_list:
+        - some_key: Some value
+          some_other_key: Some other value $vars.third
+    vars:
+      second: true
+      third: config_third 
+`
+
+var configurationsWithScopedVars = `
+vars:
+  port: 4000
+configurations:
+  http:
+    content:
+      protocols:
+        http:
+          endpoint: 0.0.0.0:$vars.port
+    vars:
+      port: 4318
+  grpc:
+    content:
+      protocols:
+        grpc:
+          endpoint: 0.0.0.0:$vars.port
+    vars:
+      port: 4317
+  default_port:
+    content:
+      default_endpoint: 0.0.0.0:$vars.port
+    append:
+      - path: "$"
+        content:
+          appended_endpoint: 0.0.0.0:$vars.port
+`
+
+var configurationsWithInheritance = `
+vars:
+  endpoint: default_endpoint
+refs:
+  base:
+    endpoint: $vars.endpoint
+    tls: $refs.tls
+configurations:
+  default:
+    content: $refs.base
+    refs:
+      tls:
+        insecure: false
+  insecure:
+    extends: default
+    refs:
+      tls:
+        insecure: true
+  custom_endpoint:
+    extends: insecure
+    vars:
+      endpoint: custom_endpoint
+    append:
+      - path: "$"
+        content:
+          headers:
+            - authorization
+  with_compression:
+    content: {}
+    append:
+      - path: "$"
+        content:
+          compression: gzip
+  combined:
+    extends: [ custom_endpoint, with_compression ]
+    content: $refs.base
+  cycle_a:
+    extends: cycle_b
+  cycle_b:
+    extends: [ default, cycle_a ]
+  orphan:
+    extends: missing
+  ambiguous:
+    extends: [ default, with_compression ]
+`
+
+var configurationWithInvalidVars = `
+vars:
+  one: valid
+  two:
+    default:
+      not_valid: true
+configurations:
+  default:
+    content: {}
+`
+
+var configurationWithDeclaredVars = `
+vars:
+  endpoint:
+    description: The endpoint to send data to
+    type: string
+    required: true
+  port:
+    description: The port to listen on
+    type: int
+    default: 4318
+  compression:
+    type: bool
+    default: false
+  ratio:
+    type: number
+    default: 0.5
+configurations:
+  default:
+    content:
+      endpoint: $vars.endpoint
+      listen: 0.0.0.0:$vars.port
+      compression: $vars.compression
+      ratio: $vars.ratio
+  with_timeout:
+    content:
+      endpoint: $vars.endpoint
+      timeout: $vars.timeout
+    vars:
+      timeout: 5s
+`
+
+var configurationWithMissingVars = `
+vars:
+  first: global_first
+configurations:
+  default:
+    content:
+      first_placeholder: $vars.first
+      second_placeholder: $vars.second
+`
+
+var configurationWithRefs = `
+vars:
+  first: global_first
+  second: global_second
+refs:
+  base:
+    first_placeholder: $vars.first
+    second_placeholder: $vars.second
+    some_key: some value
+    some_other_ref: $refs.config_details
+configurations:
+  default:
+    content: $refs.base
+    refs:
+      config_details:
+        config_key: some config value
+    vars:
+      second: config_second
+`
+
+var appendingToConfiguration = `
+vars:
+  first: global_first
+  second: global_second
+refs:
+  base:
+    first_placeholder: $vars.first
+    second_placeholder: $vars.second
+    some_map:
+      first_key: first value
+    some_list:
+      - Monday
+      - Tuesday
+      - Wednesday
+configurations:
+  default:
+    content: $refs.base
+    append:
+      - path: "$"
+        content:
+          assed_to_root: root value
+      - path: "$.some_map"
+        content:
+          added_to_child_map: child map value with $vars.second
+      - path: "$.some_list"
+        content:
+          - Thursday
+          - Friday
+          - $vars.first
+    vars:
+      second: config_second
+`
+
+func TestBuildComponent(t *testing.T) {
+	for _, tc := range []struct {
+		testName             string
+		input                string
+		componentName        string
+		configurations       []string
+		vars                 map[string]any
+		expectedResult       map[string]any
+		expectedErrorMessage string
+		shouldFail           bool
+	}{
+		{
+			testName:       "select configuration",
+			input:          simpleConfiguration,
+			componentName:  "elasticsearch",
+			configurations: []string{"someconfig"},
+			expectedResult: map[string]any{
+				"elasticsearch": map[string]any{
+					"endpoint": "someconfig_endpoint",
+					"api_key":  "someconfig_api_key",
+				},
+			},
+		},
+		{
+			testName:       "using default config when none provided",
+			input:          simpleConfiguration,
+			componentName:  "elasticsearch",
+			configurations: []string{},
+			expectedResult: map[string]any{
+				"elasticsearch": map[string]any{
+					"endpoint": "default_endpoint",
+					"api_key":  "default_api_key",
+				},
+			},
+		},
+		{
+			testName:       "defining custom config key name",
+			input:          simpleConfiguration,
+			componentName:  "elasticsearch/my_name",
+			configurations: []string{},
+			expectedResult: map[string]any{
+				"elasticsearch/my_name": map[string]any{
+					"endpoint": "default_endpoint",
+					"api_key":  "default_api_key",
+				},
+			},
+		},
+		{
+			testName:       "merging configurations",
+			input:          mergeableConfiguration,
+			componentName:  "otlp",
+			configurations: []string{"http", "grpc"},
+			expectedResult: map[string]any{
+				"otlp": map[string]any{
+					"protocol": map[string]any{
+						"http": map[string]any{
+							"endpoint": "http_endpoint",
+						},
+						"grpc": map[string]any{
+							"endpoint": "grpc_endpoint",
+						},
+					},
+				},
+			},
+		},
+		{
+			testName:             "fail merging configurations",
+			input:                unmergeableConfiguration,
+			componentName:        "otlp",
+			configurations:       []string{"first", "second"},
+			shouldFail:           true,
+			expectedErrorMessage: "merge conflict at 'protocol.http.endpoint' between configuration 'first' and configuration 'second': key overlap",
+		},
+		{
+			testName:       "appending lists by default",
+			input:          configurationsWithLists,
+			componentName:  "otlphttp",
+			configurations: []string{"first", "second"},
+			expectedResult: map[string]any{
+				"otlphttp": map[string]any{
+					"headers":  []any{"accept", "user-agent", "user-agent", "authorization"},
+					"endpoint": "first_endpoint",
+				},
+			},
+		},
+		{
+			testName:       "appending unique list items",
+			input:          configurationsWithLists,
+			componentName:  "otlphttp",
+			configurations: []string{"first", "unique"},
+			expectedResult: map[string]any{
+				"otlphttp": map[string]any{
+					"headers":  []any{"accept", "user-agent", "authorization"},
+					"endpoint": "first_endpoint",
+				},
+			},
+		},
+		{
+			testName:       "replacing values",
+			input:          configurationsWithLists,
+			componentName:  "otlphttp",
+			configurations: []string{"first", "replacing"},
+			expectedResult: map[string]any{
+				"otlphttp": map[string]any{
+					"headers":  []any{"authorization"},
+					"endpoint": "replaced_endpoint",
+				},
+			},
+		},
+		{
+			testName:       "appending unique items to a list",
+			input:          configurationsWithLists,
+			componentName:  "otlphttp",
+			configurations: []string{"first", "appending"},
+			expectedResult: map[string]any{
+				"otlphttp": map[string]any{
+					"headers":  []any{"accept", "user-agent", "content-type"},
+					"endpoint": "first_endpoint",
+				},
+			},
+		},
+		{
+			testName:             "fail merging lists with error strategy",
+			input:                configurationsWithLists,
+			componentName:        "otlphttp",
+			configurations:       []string{"first", "strict"},
+			shouldFail:           true,
+			expectedErrorMessage: "merge conflict at 'headers' between configuration 'first' and configuration 'strict': list overlap",
+		},
+		{
+			testName:             "fail merging mismatched types",
+			input:                configurationsWithLists,
+			componentName:        "otlphttp",
+			configurations:       []string{"first", "mismatched"},
+			shouldFail:           true,
+			expectedErrorMessage: "merge conflict at 'endpoint' between configuration 'first' and configuration 'mismatched': type mismatch, cannot merge map into string",
+		},
+		{
+			testName:       "variables overriding",
+			input:          configurationWithVars,
+			componentName:  "dummy",
+			configurations: []string{"default"},
+			vars: map[string]any{
+				"third":  "external_third",
+				"fourth": "external_fourth",
+			},
+			expectedResult: map[string]any{
+				"dummy": map[string]any{
+					"first_placeholder":  "global_first",
+					"second_placeholder": true,
+					"third_placeholder":  "external_third",
+					"some_list":          []any{"The fourth is external_fourth"},
+					"some_map_list": []any{
+						map[string]any{
+							"some_key":       "Some value",
+							"some_other_key": "Some other value external_third",
+						},
+					},
+				},
+			},
+		},
+		{
+			testName:       "isolating vars per configuration",
+			input:          configurationsWithScopedVars,
+			componentName:  "otlp",
+			configurations: []string{"http", "grpc", "default_port"},
+			expectedResult: map[string]any{
+				"otlp": map[string]any{
+					"protocols": map[string]any{
+						"http": map[string]any{
+							"endpoint": "0.0.0.0:4318",
+						},
+						"grpc": map[string]any{
+							"endpoint": "0.0.0.0:4317",
+						},
+					},
+					"default_endpoint":  "0.0.0.0:4000",
+					"appended_endpoint": "0.0.0.0:4000",
+				},
+			},
+		},
+		{
+			testName:       "extending a configuration",
+			input:          configurationsWithInheritance,
+			componentName:  "otlphttp",
+			configurations: []string{"insecure"},
+			expectedResult: map[string]any{
+				"otlphttp": map[string]any{
+					"endpoint": "default_endpoint",
+					"tls": map[string]any{
+						"insecure": true,
+					},
+				},
+			},
+		},
+		{
+			testName:       "extending a configuration transitively",
+			input:          configurationsWithInheritance,
+			componentName:  "otlphttp",
+			configurations: []string{"custom_endpoint"},
+			expectedResult: map[string]any{
+				"otlphttp": map[string]any{
+					"endpoint": "custom_endpoint",
+					"tls": map[string]any{
+						"insecure": true,
+					},
+					"headers": []any{"authorization"},
+				},
+			},
+		},
+		{
+			testName:       "extending multiple configurations",
+			input:          configurationsWithInheritance,
+			componentName:  "otlphttp",
+			configurations: []string{"combined"},
+			expectedResult: map[string]any{
+				"otlphttp": map[string]any{
+					"endpoint": "custom_endpoint",
+					"tls": map[string]any{
+						"insecure": true,
+					},
+					"headers":     []any{"authorization"},
+					"compression": "gzip",
+				},
+			},
+		},
+		{
+			testName:             "fail on inheritance cycles",
+			input:                configurationsWithInheritance,
+			componentName:        "otlphttp",
+			configurations:       []string{"cycle_a"},
+			shouldFail:           true,
+			expectedErrorMessage: "configuration inheritance cycle: cycle_a -> cycle_b -> cycle_a",
+		},
+		{
+			testName:             "fail extending unknown configurations",
+			input:                configurationsWithInheritance,
+			componentName:        "otlphttp",
+			configurations:       []string{"orphan"},
+			shouldFail:           true,
+			expectedErrorMessage: "configuration 'orphan' extends an unknown configuration: 'missing'",
+		},
+		{
+			testName:             "fail inheriting content from several configurations",
+			input:                configurationsWithInheritance,
+			componentName:        "otlphttp",
+			configurations:       []string{"ambiguous"},
+			shouldFail:           true,
+			expectedErrorMessage: "configuration 'ambiguous' inherits content from several configurations: 'default', 'with_compression', only one parent can provide it unless the configuration defines its own",
+		},
+		{
+			testName:             "invalid var format",
+			input:                configurationWithInvalidVars,
+			componentName:        "dummy",
+			expectedErrorMessage: "'$vars.two' format is not valid, only primitives are allowed",
+			shouldFail:           true,
+		},
+		{
+			testName:       "declared variables",
+			input:          configurationWithDeclaredVars,
+			componentName:  "dummy",
+			configurations: []string{"default"},
+			vars: map[string]any{
+				"endpoint":    "http://localhost:4318",
+				"port":        "4000",
+				"compression": "true",
+				"ratio":       "1",
+			},
+			expectedResult: map[string]any{
+				"dummy": map[string]any{
+					"endpoint":    "http://localhost:4318",
+					"listen":      "0.0.0.0:4000",
+					"compression": true,
+					"ratio":       int64(1),
+				},
+			},
+		},
+		{
+			testName:       "variables declared in configurations",
+			input:          configurationWithDeclaredVars,
+			componentName:  "dummy",
+			configurations: []string{"with_timeout"},
+			vars: map[string]any{
+				"endpoint": "http://localhost:4318",
+				"timeout":  "10s",
+			},
+			expectedResult: map[string]any{
+				"dummy": map[string]any{
+					"endpoint": "http://localhost:4318",
+					"timeout":  "10s",
+				},
+			},
+		},
+		{
+			testName:      "unknown variable",
+			input:         configurationWithDeclaredVars,
+			componentName: "dummy",
+			vars: map[string]any{
+				"endpoint": "http://localhost:4318",
+				"prot":     4000,
+			},
+			expectedErrorMessage: "unknown var 'prot' provided, the declared vars are: [compression endpoint port ratio timeout]",
+			shouldFail:           true,
+		},
+		{
+			testName:             "required variable",
+			input:                configurationWithDeclaredVars,
+			componentName:        "dummy",
+			expectedErrorMessage: "var 'endpoint' is required but it wasn't provided",
+			shouldFail:           true,
+		},
+		{
+			testName:      "variable type mismatch",
+			input:         configurationWithDeclaredVars,
+			componentName: "dummy",
+			vars: map[string]any{
+				"endpoint": "http://localhost:4318",
+				"port":     "not-a-port",
+			},
+			expectedErrorMessage: "var 'port' must be of type int, got string 'not-a-port'",
+			shouldFail:           true,
+		},
+		{
+			testName:             "missing variable",
+			input:                configurationWithMissingVars,
+			componentName:        "dummy",
+			configurations:       []string{"default"},
+			expectedErrorMessage: "'$vars.second' is not defined, the available values are: map[$vars.first:global_first]",
+			shouldFail:           true,
+		},
+		{
+			testName:       "config with refs",
+			input:          configurationWithRefs,
+			componentName:  "dummy",
+			configurations: []string{"default"},
+			expectedResult: map[string]any{
+				"dummy": map[string]any{
+					"first_placeholder":  "global_first",
+					"second_placeholder": "config_second",
+					"some_key":           "some value",
+					"some_other_ref": map[string]any{
+						"config_key": "some config value",
+					},
+				},
+			},
+		},
+		{
+			testName:       "appending to config",
+			input:          appendingToConfiguration,
+			componentName:  "dummy",
+			configurations: []string{"default"},
+			expectedResult: map[string]any{
+				"dummy": map[string]any{
+					"first_placeholder":  "global_first",
+					"second_placeholder": "config_second",
+					"some_map": map[string]any{
+						"first_key":          "first value",
+						"added_to_child_map": "child map value with config_second",
+					},
+					"some_list": []any{
+						"Monday",
+						"Tuesday",
+						"Wednesday",
+						"Thursday",
+						"Friday",
+						"global_first",
+					},
+					"assed_to_root": "root value",
+				},
+			},
+		},
+		{
+			testName: "integer float variable",
+			input: `
+vars:
+  ratio:
+    type: float
+configurations:
+  default:
+    content:
+      ratio: $vars.ratio
+`,
+			componentName:  "dummy",
+			configurations: []string{"default"},
+			vars:           map[string]any{"ratio": 1},
+			expectedResult: map[string]any{
+				"dummy": map[string]any{
+					"ratio": float64(1),
+				},
+			},
+		},
+		{
+			testName: "fail on lists for scalar typed variables",
+			input: `
+vars:
+  endpoint:
+    type: string
+configurations:
+  default:
+    content:
+      endpoint: $vars.endpoint
+`,
+			componentName:        "dummy",
+			configurations:       []string{"default"},
+			vars:                 map[string]any{"endpoint": []any{"a"}},
+			expectedErrorMessage: "var 'endpoint' must be of type string, got slice",
+			shouldFail:           true,
+		},
+		{
+			testName: "fail appending a list to the root",
+			input: `
+configurations:
+  default:
+    content:
+      some_key: some value
+    append:
+      - path: "$"
+        content: [ item ]
+`,
+			componentName:        "dummy",
+			configurations:       []string{"default"},
+			expectedErrorMessage: "could not append a list to the root of the configuration, the yaml path must point to a list",
+			shouldFail:           true,
+		},
+		{
+			testName: "fail on refs that aren't maps",
+			input: `
+refs:
+  base: [ item ]
+configurations:
+  default:
+    content: $refs.base
+`,
+			componentName:        "dummy",
+			configurations:       []string{"default"},
+			expectedErrorMessage: "'$refs.base' (within a component string '$refs.base') must be a map, it's: slice",
+			shouldFail:           true,
+		},
+		{
+			testName: "fail on nested refs that aren't maps",
+			input: `
+refs:
+  protocol: http
+configurations:
+  default:
+    content:
+      protocols: $refs.protocol
+`,
+			componentName:        "dummy",
+			configurations:       []string{"default"},
+			expectedErrorMessage: "'$refs.protocol' (within a component string '$refs.protocol') must be a map, it's: string",
+			shouldFail:           true,
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			result, err := BuildComponent(strings.NewReader(tc.input), ComponentParams{
+				Name:               tc.componentName,
+				ConfigurationNames: tc.configurations,
+				Vars:               tc.vars,
+			})
+
+			if tc.shouldFail {
+				assert.EqualError(t, err, tc.expectedErrorMessage)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedResult, result)
+			}
+		})
+	}
+}
+
+func TestBuildComponentTrace(t *testing.T) {
+	var resolutions []Resolution
+	origins := make(map[string]string)
+	_, err := BuildComponent(strings.NewReader(configurationsWithScopedVars), ComponentParams{
+		Name:               "otlp",
+		ConfigurationNames: []string{"http", "default_port"},
+		Trace: func(r Resolution) {
+			if r.Placeholder == "" {
+				origins[JoinPath(r.Path)] = r.Configuration
+				return
+			}
+			resolutions = append(resolutions, r)
+		},
+	})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []Resolution{
+		{
+			Path:          []string{"protocols", "http", "endpoint"},
+			Text:          "0.0.0.0:$vars.port",
+			Placeholder:   "$vars.port",
+			Value:         uint64(4318),
+			Scope:         "configuration 'http' vars",
+			Configuration: "http",
+		},
+		{
+			Path:          []string{"default_endpoint"},
+			Text:          "0.0.0.0:$vars.port",
+			Placeholder:   "$vars.port",
+			Value:         uint64(4000),
+			Scope:         "component vars",
+			Configuration: "default_port",
+		},
+		{
+			Path:          []string{"appended_endpoint"},
+			Text:          "0.0.0.0:$vars.port",
+			Placeholder:   "$vars.port",
+			Value:         uint64(4000),
+			Scope:         "component vars",
+			Configuration: "default_port",
+		},
+	}, resolutions)
+	assert.Equal(t, map[string]string{
+		"protocols.http.endpoint": "http",
+		"default_endpoint":        "default_port",
+		"appended_endpoint":       "default_port",
+	}, origins)
+}
+
+func TestYamlPathParsing(t *testing.T) {
+	for _, tc := range []struct {
+		testName             string
+		input                string
+		expectedOutput       []string
+		expectedErrorMessage string
+		shouldFail           bool
+	}{
+		{
+			testName:       "simple path",
+			input:          "$.one.path",
+			expectedOutput: []string{"one", "path"},
+		},
+		{
+			testName:       "root path",
+			input:          "$",
+			expectedOutput: []string{},
+		},
+		{
+			testName:       "path with dot",
+			input:          "$.some.path.'with.dot'.other",
+			expectedOutput: []string{"some", "path", "with.dot", "other"},
+		},
+		{
+			testName:             "invalid path",
+			input:                "$.",
+			expectedErrorMessage: "invalid yaml path: $.",
+			shouldFail:           true,
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			items, err := parseYamlPath(tc.input)
+			if tc.shouldFail {
+				assert.EqualError(t, err, tc.expectedErrorMessage)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedOutput, items)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package configurator
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+)
+
+type ComponentTest struct {
+	Name           string `validate:"required"`
+	Configurations []string
+	Vars           map[string]any
+	Expected       any
+	Error          string
+}
+
+// RunComponentTests runs the tests of every component file within dir, and lists the component files that have none.
+func RunComponentTests(components fs.FS, dir string) ([]TestResult, []string, error) {
+	var results []TestResult
+	var uncovered []string
+	err := fs.WalkDir(components, dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !yamlFileNamePattern.MatchString(d.Name()) {
+			return err
+		}
+		componentResults, err := runComponentFileTests(components, filePath)
+		if err != nil {
+			return err
+		}
+		if len(componentResults) == 0 {
+			uncovered = append(uncovered, filePath)
+		}
+		results = append(results, componentResults...)
+		return nil
+	})
+	return results, uncovered, err
+}
+
+func runComponentFileTests(components fs.FS, componentFilePath string) ([]TestResult, error) {
+	data, err := fs.ReadFile(components, componentFilePath)
+	if err != nil {
+		return nil, err
+	}
+	component, err := ParseComponent(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("could not load component '%s': %w", componentFilePath, err)
+	}
+	var results []TestResult
+	for _, test := range component.Tests {
+		result := TestResult{Name: fmt.Sprintf("%s (%s)", componentFilePath, test.Name)}
+		result.Failure, err = runComponentTest(data, test)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func runComponentTest(data []byte, test ComponentTest) (string, error) {
+	built, err := BuildComponent(bytes.NewReader(data), ComponentParams{
+		Name:               "test",
+		ConfigurationNames: test.Configurations,
+		Vars:               test.Vars,
+	})
+	switch {
+	case test.Error != "" && test.Expected != nil:
+		return "the test must define either its expected content or its expected error, not both\n", nil
+	case test.Error != "" && err == nil:
+		return fmt.Sprintf("expected error '%s', but the component was built\n", test.Error), nil
+	case test.Error != "" && err.Error() != test.Error:
+		return fmt.Sprintf("expected error '%s', got '%v'\n", test.Error, err), nil
+	case test.Error != "":
+		return "", nil
+	case err != nil:
+		return fmt.Sprintf("could not build the component: %v\n", err), nil
+	case test.Expected == nil:
+		return "the test must define either its expected content or its expected error\n", nil
+	}
+	entries := Diff(test.Expected, built["test"])
+	if len(entries) == 0 {
+		return "", nil
+	}
+	text, err := FormatDiff(entries, "text")
+	return string(text), err
+}
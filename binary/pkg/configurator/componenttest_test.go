@@ -0,0 +1,104 @@
+package configurator
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponentFilesTests(t *testing.T) {
+	results, uncovered, err := RunComponentTests(os.DirFS("../../../components"), ".")
+	assert.NoError(t, err)
+	assert.Empty(t, uncovered, "every component needs at least one test")
+	assert.NotEmpty(t, results)
+
+	for _, result := range results {
+		assert.Empty(t, result.Failure, result.Name)
+	}
+}
+
+func TestRunComponentTests(t *testing.T) {
+	componentsDir := writeFiles(t, map[string]string{
+		"exporters/otlp.yml": `
+vars:
+  endpoint:
+    required: true
+  compression:
+    default: gzip
+configurations:
+  default:
+    content:
+      endpoint: $vars.endpoint
+      compression: $vars.compression
+  insecure:
+    content:
+      tls:
+        insecure: true
+tests:
+  - name: passing
+    configurations: [ default, insecure ]
+    vars:
+      endpoint: localhost:4317
+    expected:
+      endpoint: localhost:4317
+      compression: gzip
+      tls:
+        insecure: true
+  - name: failing
+    vars:
+      endpoint: localhost:4317
+      compression: none
+    expected:
+      endpoint: localhost:4318
+      compression: none
+      retry_on_failure:
+        enabled: true
+  - name: expected error
+    error: var 'endpoint' is required but it wasn't provided
+  - name: unexpected error
+    configurations: [ unknown ]
+    vars:
+      endpoint: localhost:4317
+    expected: {}
+  - name: missing expectation
+    vars:
+      endpoint: localhost:4317
+  - name: both expectations
+    expected: {}
+    error: var 'endpoint' is required but it wasn't provided
+`,
+		"exporters/debug.yml": `
+configurations:
+  default:
+    content: {}
+`,
+	})
+
+	results, uncovered, err := RunComponentTests(os.DirFS(componentsDir), ".")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"exporters/debug.yml"}, uncovered)
+
+	otlpPath := "exporters/otlp.yml"
+	assert.Equal(t, []TestResult{
+		{Name: otlpPath + " (passing)"},
+		{
+			Name:    otlpPath + " (failing)",
+			Failure: "~ endpoint: localhost:4318 -> localhost:4317\n- retry_on_failure: {\"enabled\":true}\n\n0 added, 1 removed, 1 changed, 0 reordered\n",
+		},
+		{Name: otlpPath + " (expected error)"},
+		{
+			Name:    otlpPath + " (unexpected error)",
+			Failure: "could not build the component: couldn't find configuration named 'unknown'\n",
+		},
+		{
+			Name:    otlpPath + " (missing expectation)",
+			Failure: "the test must define either its expected content or its expected error\n",
+		},
+		{
+			Name:    otlpPath + " (both expectations)",
+			Failure: "the test must define either its expected content or its expected error, not both\n",
+		},
+	}, results)
+	assert.True(t, HasTestFailures(results))
+}
@@ -0,0 +1,183 @@
+package configurator
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+type DiffKind string
+
+const (
+	DiffAdded     DiffKind = "added"
+	DiffRemoved   DiffKind = "removed"
+	DiffChanged   DiffKind = "changed"
+	DiffReordered DiffKind = "reordered"
+)
+
+type DiffEntry struct {
+	Kind DiffKind `json:"kind"`
+	Path string   `json:"path"`
+	Old  any      `json:"old"`
+	New  any      `json:"new"`
+}
+
+func Diff(old any, new any) []DiffEntry {
+	return diffValues(old, new, []string{})
+}
+
+func diffValues(old any, new any, path []string) []DiffEntry {
+	// The collector reads a key without a value, e.g. `debug:`, as an empty map.
+	if old == nil && isMap(new) {
+		old = map[string]any{}
+	}
+	if new == nil && isMap(old) {
+		new = map[string]any{}
+	}
+	switch {
+	case isMap(old) && isMap(new):
+		return diffMaps(old.(map[string]any), new.(map[string]any), path)
+	case isSlice(old) && isSlice(new):
+		return diffLists(old.([]any), new.([]any), path)
+	case isMap(old) || isMap(new) || isSlice(old) || isSlice(new) || !scalarsEqual(old, new):
+		return []DiffEntry{{Kind: DiffChanged, Path: JoinPath(path), Old: old, New: new}}
+	}
+	return nil
+}
+
+func diffMaps(old map[string]any, new map[string]any, path []string) []DiffEntry {
+	keys := slices.Collect(maps.Keys(old))
+	for k := range new {
+		if _, ok := old[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	var entries []DiffEntry
+	for _, k := range keys {
+		keyPath := append(slices.Clone(path), k)
+		oldValue, inOld := old[k]
+		newValue, inNew := new[k]
+		switch {
+		case !inOld:
+			entries = append(entries, DiffEntry{Kind: DiffAdded, Path: JoinPath(keyPath), New: newValue})
+		case !inNew:
+			entries = append(entries, DiffEntry{Kind: DiffRemoved, Path: JoinPath(keyPath), Old: oldValue})
+		default:
+			entries = append(entries, diffValues(oldValue, newValue, keyPath)...)
+		}
+	}
+	return entries
+}
+
+func diffLists(old []any, new []any, path []string) []DiffEntry {
+	if slices.ContainsFunc(old, isCollection) || slices.ContainsFunc(new, isCollection) {
+		var entries []DiffEntry
+		for i := range max(len(old), len(new)) {
+			itemPath := append(slices.Clone(path), fmt.Sprintf("[%d]", i))
+			switch {
+			case i >= len(old):
+				entries = append(entries, DiffEntry{Kind: DiffAdded, Path: JoinPath(itemPath), New: new[i]})
+			case i >= len(new):
+				entries = append(entries, DiffEntry{Kind: DiffRemoved, Path: JoinPath(itemPath), Old: old[i]})
+			default:
+				entries = append(entries, diffValues(old[i], new[i], itemPath)...)
+			}
+		}
+		return entries
+	}
+
+	var entries []DiffEntry
+	var oldCommon, newCommon []any
+	matched := make([]bool, len(new))
+	for _, item := range old {
+		match := -1
+		for i, other := range new {
+			if !matched[i] && scalarsEqual(item, other) {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			entries = append(entries, DiffEntry{Kind: DiffRemoved, Path: JoinPath(path), Old: item})
+			continue
+		}
+		matched[match] = true
+		oldCommon = append(oldCommon, item)
+	}
+	for i, item := range new {
+		if matched[i] {
+			newCommon = append(newCommon, item)
+		} else {
+			entries = append(entries, DiffEntry{Kind: DiffAdded, Path: JoinPath(path), New: item})
+		}
+	}
+	if !slices.EqualFunc(oldCommon, newCommon, scalarsEqual) {
+		entries = append(entries, DiffEntry{Kind: DiffReordered, Path: JoinPath(path), Old: old, New: new})
+	}
+	return entries
+}
+
+func isCollection(value any) bool {
+	return isMap(value) || isSlice(value)
+}
+
+func scalarsEqual(a any, b any) bool {
+	return jsonEqual(a, b) || (a != nil && b != nil && fmt.Sprint(a) == fmt.Sprint(b))
+}
+
+func FormatDiff(entries []DiffEntry, format string) ([]byte, error) {
+	switch format {
+	case "text":
+		var text strings.Builder
+		if len(entries) == 0 {
+			text.WriteString("No differences found.\n")
+			return []byte(text.String()), nil
+		}
+		counts := make(map[DiffKind]int)
+		var reordered []DiffEntry
+		for _, entry := range entries {
+			counts[entry.Kind]++
+			switch entry.Kind {
+			case DiffAdded:
+				fmt.Fprintf(&text, "+ %s: %s\n", entry.Path, formatDiffValue(entry.New))
+			case DiffRemoved:
+				fmt.Fprintf(&text, "- %s: %s\n", entry.Path, formatDiffValue(entry.Old))
+			case DiffChanged:
+				fmt.Fprintf(&text, "~ %s: %s -> %s\n", entry.Path, formatDiffValue(entry.Old), formatDiffValue(entry.New))
+			case DiffReordered:
+				reordered = append(reordered, entry)
+			}
+		}
+		if len(reordered) > 0 {
+			if len(reordered) < len(entries) {
+				text.WriteString("\n")
+			}
+			text.WriteString("Reordered lists:\n")
+			for _, entry := range reordered {
+				fmt.Fprintf(&text, "  %s: %s -> %s\n", entry.Path, formatDiffValue(entry.Old), formatDiffValue(entry.New))
+			}
+		}
+		fmt.Fprintf(&text, "\n%d added, %d removed, %d changed, %d reordered\n", counts[DiffAdded], counts[DiffRemoved], counts[DiffChanged], counts[DiffReordered])
+		return []byte(text.String()), nil
+	case "json":
+		if entries == nil {
+			entries = []DiffEntry{}
+		}
+		return marshalJsonLine(entries)
+	}
+	return nil, fmt.Errorf("unknown output format '%s', must be one of: text, json", format)
+}
+
+func formatDiffValue(value any) string {
+	if !isCollection(value) {
+		return fmt.Sprint(value)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,233 @@
+package configurator
+
+import (
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffConfigurations(t *testing.T) {
+	for _, tc := range []struct {
+		testName string
+		old      string
+		new      string
+		expected []DiffEntry
+	}{
+		{
+			testName: "Ignores formatting and key order",
+			old: `
+receivers: { otlp: { protocols: { grpc: {}, http: {} } } }
+exporters:
+  debug:
+    verbosity: detailed
+`,
+			new: `
+exporters: { debug: { verbosity: "detailed" } }
+receivers:
+  otlp:
+    protocols:
+      http: {}
+      grpc: {}
+`,
+		},
+		{
+			testName: "Compares scalars by value",
+			old: `
+processors:
+  batch:
+    send_batch_size: "1000"
+    enabled: "true"
+`,
+			new: `
+processors:
+  batch:
+    send_batch_size: 1000
+    enabled: true
+`,
+		},
+		{
+			testName: "Reports added, removed and changed keys",
+			old: `
+processors:
+  batch:
+    timeout: 5s
+exporters:
+  otlp/old:
+    endpoint: old:4317
+`,
+			new: `
+processors:
+  batch:
+    timeout: 1s
+    send_batch_size: 1000
+exporters: {}
+`,
+			expected: []DiffEntry{
+				{Kind: DiffRemoved, Path: "exporters.otlp/old", Old: map[string]any{"endpoint": "old:4317"}},
+				{Kind: DiffAdded, Path: "processors.batch.send_batch_size", New: uint64(1000)},
+				{Kind: DiffChanged, Path: "processors.batch.timeout", Old: "5s", New: "1s"},
+			},
+		},
+		{
+			testName: "Reports a type change as changed",
+			old: `
+receivers:
+  otlp: localhost:4317
+`,
+			new: `
+receivers:
+  otlp:
+    protocols: {}
+`,
+			expected: []DiffEntry{
+				{Kind: DiffChanged, Path: "receivers.otlp", Old: "localhost:4317", New: map[string]any{"protocols": map[string]any{}}},
+			},
+		},
+		{
+			testName: "Treats null as an empty map",
+			old: `
+exporters:
+  debug:
+receivers:
+  otlp:
+`,
+			new: `
+exporters:
+  debug: {}
+receivers:
+  otlp:
+    protocols: {}
+`,
+			expected: []DiffEntry{
+				{Kind: DiffAdded, Path: "receivers.otlp.protocols", New: map[string]any{}},
+			},
+		},
+		{
+			testName: "Compares scalar lists by their items",
+			old: `
+service:
+  pipelines:
+    traces:
+      processors: [ memory_limiter, batch ]
+`,
+			new: `
+service:
+  pipelines:
+    traces:
+      processors: [ memory_limiter, elasticapm ]
+`,
+			expected: []DiffEntry{
+				{Kind: DiffRemoved, Path: "service.pipelines.traces.processors", Old: "batch"},
+				{Kind: DiffAdded, Path: "service.pipelines.traces.processors", New: "elasticapm"},
+			},
+		},
+		{
+			testName: "Counts duplicated scalars",
+			old: `
+service:
+  pipelines:
+    traces:
+      processors: [ batch, batch, memory_limiter ]
+      exporters: [ debug ]
+`,
+			new: `
+service:
+  pipelines:
+    traces:
+      processors: [ batch, memory_limiter ]
+      exporters: [ debug, debug ]
+`,
+			expected: []DiffEntry{
+				{Kind: DiffAdded, Path: "service.pipelines.traces.exporters", New: "debug"},
+				{Kind: DiffRemoved, Path: "service.pipelines.traces.processors", Old: "batch"},
+			},
+		},
+		{
+			testName: "Reports reordered lists separately",
+			old: `
+service:
+  pipelines:
+    traces:
+      processors: [ batch, memory_limiter ]
+`,
+			new: `
+service:
+  pipelines:
+    traces:
+      processors: [ memory_limiter, batch ]
+`,
+			expected: []DiffEntry{
+				{
+					Kind: DiffReordered,
+					Path: "service.pipelines.traces.processors",
+					Old:  []any{"batch", "memory_limiter"},
+					New:  []any{"memory_limiter", "batch"},
+				},
+			},
+		},
+		{
+			testName: "Compares lists of maps by index",
+			old: `
+processors:
+  transform:
+    statements:
+      - context: span
+      - context: log
+`,
+			new: `
+processors:
+  transform:
+    statements:
+      - context: resource
+`,
+			expected: []DiffEntry{
+				{Kind: DiffChanged, Path: "processors.transform.statements[0].context", Old: "span", New: "resource"},
+				{Kind: DiffRemoved, Path: "processors.transform.statements[1]", Old: map[string]any{"context": "log"}},
+			},
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			var old, new any
+			assert.NoError(t, yaml.Unmarshal([]byte(tc.old), &old))
+			assert.NoError(t, yaml.Unmarshal([]byte(tc.new), &new))
+			assert.Equal(t, tc.expected, Diff(old, new))
+		})
+	}
+}
+
+func TestFormatDiff(t *testing.T) {
+	entries := []DiffEntry{
+		{Kind: DiffRemoved, Path: "exporters.otlp/old", Old: map[string]any{"endpoint": "old:4317"}},
+		{Kind: DiffAdded, Path: "processors.batch.send_batch_size", New: 1000},
+		{Kind: DiffChanged, Path: "processors.batch.timeout", Old: "5s", New: "1s"},
+		{Kind: DiffReordered, Path: "service.pipelines.traces.processors", Old: []any{"batch", "memory_limiter"}, New: []any{"memory_limiter", "batch"}},
+	}
+
+	text, err := FormatDiff(entries, "text")
+	assert.NoError(t, err)
+	assert.Equal(t, `- exporters.otlp/old: {"endpoint":"old:4317"}
++ processors.batch.send_batch_size: 1000
+~ processors.batch.timeout: 5s -> 1s
+
+Reordered lists:
+  service.pipelines.traces.processors: ["batch","memory_limiter"] -> ["memory_limiter","batch"]
+
+1 added, 1 removed, 1 changed, 1 reordered
+`, string(text))
+
+	text, err = FormatDiff(nil, "text")
+	assert.NoError(t, err)
+	assert.Equal(t, "No differences found.\n", string(text))
+
+	data, err := FormatDiff(entries[2:3], "json")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"kind":"changed","path":"processors.batch.timeout","old":"5s","new":"1s"}]`, string(data))
+
+	data, err = FormatDiff(nil, "json")
+	assert.NoError(t, err)
+	assert.JSONEq(t, "[]", string(data))
+
+	_, err = FormatDiff(entries, "sarif")
+	assert.EqualError(t, err, "unknown output format 'sarif', must be one of: text, json")
+}
@@ -0,0 +1,151 @@
+package configurator
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// listenEndpointTypes are the components whose top level endpoint is an address they listen on. Besides them, only
+// the protocols.<protocol>.endpoint of receivers and extensions are listen addresses: other endpoints, e.g. the ones
+// of scrapers, are the addresses of the servers they connect to.
+var listenEndpointTypes = []string{"health_check", "pprof", "zpages", "zipkin"}
+
+type listenEndpoint struct {
+	Address   string
+	Host      string
+	Port      int
+	Path      []string
+	Component string
+}
+
+func validateListenEndpoints(config map[string]any) error {
+	byPort := make(map[int][]listenEndpoint)
+	for _, endpoint := range findListenEndpoints(config) {
+		byPort[endpoint.Port] = append(byPort[endpoint.Port], endpoint)
+	}
+	var errs []error
+	for _, port := range slices.Sorted(maps.Keys(byPort)) {
+		endpoints := byPort[port]
+		var conflicting []string
+		for _, endpoint := range endpoints {
+			if slices.ContainsFunc(endpoints, func(other listenEndpoint) bool {
+				return JoinPath(other.Path) != JoinPath(endpoint.Path) && endpoint.overlaps(other)
+			}) {
+				conflicting = append(conflicting, fmt.Sprintf("%s (%s = %s)", endpoint.Component, JoinPath(endpoint.Path), endpoint.Address))
+			}
+		}
+		if len(conflicting) > 0 {
+			errs = append(errs, fmt.Errorf("port %d is bound more than once: %s", port, strings.Join(conflicting, ", ")))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func findListenEndpoints(config map[string]any) []listenEndpoint {
+	var endpoints []listenEndpoint
+	for _, section := range []string{"receivers", "extensions"} {
+		walkLeaves(config[section], []string{section}, func(path []string, value any) {
+			if !isListenEndpointPath(path) {
+				return
+			}
+			if endpoint, ok := parseListenEndpoint(fmt.Sprint(value)); ok {
+				endpoint.Path = path
+				endpoint.Component = JoinPath(path[:2])
+				endpoints = append(endpoints, endpoint)
+			}
+		})
+	}
+	// Only the collector's own metrics are served from service.telemetry: the other endpoints there, e.g. the ones of
+	// push exporters, are addresses it connects to.
+	metrics, _ := getValueAtPath(config, []string{"service", "telemetry", "metrics"}).(map[string]any)
+	if address, ok := metrics["address"]; ok {
+		if endpoint, ok := parseListenEndpoint(fmt.Sprint(address)); ok {
+			endpoint.Path = []string{"service", "telemetry", "metrics", "address"}
+			endpoint.Component = "service.telemetry"
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	readers, _ := metrics["readers"].([]any)
+	for i, reader := range readers {
+		prometheus, _ := getValueAtPath(reader, []string{"pull", "exporter", "prometheus"}).(map[string]any)
+		port, err := strconv.Atoi(fmt.Sprint(prometheus["port"]))
+		if err != nil {
+			continue
+		}
+		host := ""
+		if prometheus["host"] != nil {
+			host = fmt.Sprint(prometheus["host"])
+		}
+		endpoints = append(endpoints, listenEndpoint{
+			Address:   net.JoinHostPort(host, strconv.Itoa(port)),
+			Host:      normalizeHost(host),
+			Port:      port,
+			Path:      []string{"service", "telemetry", "metrics", "readers", fmt.Sprintf("[%d]", i), "pull", "exporter", "prometheus", "port"},
+			Component: "service.telemetry",
+		})
+	}
+	return endpoints
+}
+
+func isListenEndpointPath(path []string) bool {
+	if len(path) < 3 || path[len(path)-1] != "endpoint" {
+		return false
+	}
+	if len(path) == 3 {
+		componentType, _, _ := strings.Cut(path[1], "/")
+		return slices.Contains(listenEndpointTypes, componentType)
+	}
+	return len(path) >= 5 && path[len(path)-3] == "protocols"
+}
+
+func parseListenEndpoint(value string) (listenEndpoint, bool) {
+	if strings.Contains(value, "://") {
+		return listenEndpoint{}, false
+	}
+	host, portText, err := net.SplitHostPort(value)
+	if err != nil {
+		return listenEndpoint{}, false
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil {
+		return listenEndpoint{}, false
+	}
+	return listenEndpoint{Address: value, Host: normalizeHost(host), Port: port}, true
+}
+
+func normalizeHost(host string) string {
+	switch host {
+	case "", "0.0.0.0", "::":
+		return ""
+	case "localhost", "::1":
+		return "127.0.0.1"
+	}
+	return host
+}
+
+func (e listenEndpoint) overlaps(other listenEndpoint) bool {
+	return e.Port == other.Port && (e.Host == "" || other.Host == "" || e.Host == other.Host)
+}
+
+func getValueAtPath(value any, path []string) any {
+	for _, key := range path {
+		mapValue, ok := value.(map[string]any)
+		if !ok {
+			if list, ok := value.([]any); ok {
+				var index int
+				if _, err := fmt.Sscanf(key, "[%d]", &index); err == nil && index < len(list) {
+					value = list[index]
+					continue
+				}
+			}
+			return nil
+		}
+		value = mapValue[key]
+	}
+	return value
+}
@@ -0,0 +1,185 @@
+package configurator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateListenEndpoints(t *testing.T) {
+	for _, tc := range []struct {
+		testName             string
+		config               map[string]any
+		expectedErrorMessage string
+	}{
+		{
+			testName: "different ports",
+			config: map[string]any{
+				"receivers": map[string]any{
+					"otlp": map[string]any{
+						"protocols": map[string]any{
+							"grpc": map[string]any{"endpoint": "0.0.0.0:4317"},
+							"http": map[string]any{"endpoint": "0.0.0.0:4318"},
+						},
+					},
+				},
+				"extensions": map[string]any{
+					"apmconfig": map[string]any{
+						"source": map[string]any{
+							"elasticsearch": map[string]any{"endpoint": "http://localhost:4318"},
+						},
+						"opamp": map[string]any{
+							"protocols": map[string]any{
+								"http": map[string]any{"endpoint": "localhost:4320"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			testName: "same port on different hosts",
+			config: map[string]any{
+				"receivers": map[string]any{
+					"zipkin":          map[string]any{"endpoint": "10.0.0.1:4318"},
+					"zipkin/internal": map[string]any{"endpoint": "10.0.0.2:4318"},
+				},
+			},
+		},
+		{
+			testName: "client endpoints",
+			config: map[string]any{
+				"receivers": map[string]any{
+					"redis":         map[string]any{"endpoint": "localhost:6379"},
+					"redis/replica": map[string]any{"endpoint": "localhost:6379"},
+					"prometheus": map[string]any{
+						"config": map[string]any{"scrape_configs": []any{map[string]any{"endpoint": "localhost:4318"}}},
+					},
+					"otlp": map[string]any{
+						"protocols": map[string]any{
+							"http": map[string]any{"endpoint": "0.0.0.0:4318"},
+						},
+					},
+				},
+			},
+		},
+		{
+			testName: "telemetry push exporters",
+			config: map[string]any{
+				"receivers": map[string]any{
+					"otlp": map[string]any{
+						"protocols": map[string]any{
+							"grpc": map[string]any{"endpoint": "localhost:4317"},
+						},
+					},
+				},
+				"service": map[string]any{
+					"telemetry": map[string]any{
+						"metrics": map[string]any{
+							"readers": []any{
+								map[string]any{
+									"periodic": map[string]any{
+										"exporter": map[string]any{
+											"otlp": map[string]any{"protocol": "grpc", "endpoint": "localhost:4317"},
+										},
+									},
+								},
+							},
+						},
+						"logs": map[string]any{
+							"processors": []any{
+								map[string]any{
+									"batch": map[string]any{
+										"exporter": map[string]any{
+											"otlp": map[string]any{"protocol": "grpc", "endpoint": "localhost:4317"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			testName: "conflicting legacy metrics address",
+			config: map[string]any{
+				"extensions": map[string]any{
+					"health_check": map[string]any{"endpoint": "0.0.0.0:8888"},
+				},
+				"service": map[string]any{
+					"telemetry": map[string]any{
+						"metrics": map[string]any{"address": "localhost:8888"},
+					},
+				},
+			},
+			expectedErrorMessage: "port 8888 is bound more than once: " +
+				"extensions.health_check (extensions.health_check.endpoint = 0.0.0.0:8888), " +
+				"service.telemetry (service.telemetry.metrics.address = localhost:8888)",
+		},
+		{
+			testName: "conflicting top level endpoints",
+			config: map[string]any{
+				"extensions": map[string]any{
+					"health_check": map[string]any{"endpoint": "0.0.0.0:13133"},
+					"zpages":       map[string]any{"endpoint": "localhost:13133"},
+				},
+			},
+			expectedErrorMessage: "port 13133 is bound more than once: " +
+				"extensions.health_check (extensions.health_check.endpoint = 0.0.0.0:13133), " +
+				"extensions.zpages (extensions.zpages.endpoint = localhost:13133)",
+		},
+		{
+			testName: "conflicting ports",
+			config: map[string]any{
+				"receivers": map[string]any{
+					"otlp": map[string]any{
+						"protocols": map[string]any{
+							"http": map[string]any{"endpoint": "0.0.0.0:4318"},
+						},
+					},
+				},
+				"extensions": map[string]any{
+					"apmconfig": map[string]any{
+						"opamp": map[string]any{
+							"protocols": map[string]any{
+								"http": map[string]any{"endpoint": "localhost:4318"},
+							},
+						},
+					},
+				},
+				"service": map[string]any{
+					"telemetry": map[string]any{
+						"metrics": map[string]any{
+							"readers": []any{
+								map[string]any{
+									"pull": map[string]any{
+										"exporter": map[string]any{
+											"prometheus": map[string]any{
+												"host": "localhost",
+												"port": 4318,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedErrorMessage: "port 4318 is bound more than once: " +
+				"receivers.otlp (receivers.otlp.protocols.http.endpoint = 0.0.0.0:4318), " +
+				"extensions.apmconfig (extensions.apmconfig.opamp.protocols.http.endpoint = localhost:4318), " +
+				"service.telemetry (service.telemetry.metrics.readers[0].pull.exporter.prometheus.port = localhost:4318)",
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			err := validateListenEndpoints(tc.config)
+			if tc.expectedErrorMessage != "" {
+				assert.EqualError(t, err, tc.expectedErrorMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package configurator
+
+import "fmt"
+
+// ArgError is returned when a recipe arg is provided neither directly, via its env var nor by a default value.
+type ArgError struct {
+	Name string
+	Env  string
+}
+
+func (e *ArgError) Error() string {
+	return fmt.Sprintf("arg '%s' not provided - you may provide via the env var: '%s' or via the command line argument: '-A%s'", e.Name, e.Env, e.Name)
+}
+
+// ComponentError is returned when a recipe component can't be loaded or built.
+type ComponentError struct {
+	Key    string
+	Source string
+	Err    error
+}
+
+func (e *ComponentError) Error() string {
+	return fmt.Sprintf("component '%s' (%s): %v", e.Key, e.Source, e.Err)
+}
+
+func (e *ComponentError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,117 @@
+package configurator
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Explanation struct {
+	Path        []string
+	Value       any
+	Origin      *Resolution
+	Resolutions []Resolution
+}
+
+func Explain(configuration map[string]any, resolutions []Resolution, filter string) ([]Explanation, error) {
+	byPath := make(map[string][]Resolution)
+	for _, r := range resolutions {
+		key := JoinPath(r.Path)
+		byPath[key] = append(byPath[key], r)
+	}
+	var explanations []Explanation
+	walkValues(configuration, []string{}, func(path []string, value any) {
+		if len(path) == 0 || !matchesPathFilter(JoinPath(path), filter) {
+			return
+		}
+		explanation := Explanation{Path: path, Value: value}
+		for i := len(path); i > 0 && explanation.Origin == nil; i-- {
+			if traced := byPath[JoinPath(path[:i])]; len(traced) > 0 {
+				explanation.Origin = &traced[len(traced)-1]
+			}
+		}
+		for _, r := range byPath[JoinPath(path)] {
+			if r.Placeholder != "" {
+				explanation.Resolutions = append(explanation.Resolutions, r)
+			}
+		}
+		explanations = append(explanations, explanation)
+	})
+	if len(explanations) == 0 && filter != "" {
+		return nil, fmt.Errorf("no values found at '%s'", filter)
+	}
+	return explanations, nil
+}
+
+func matchesPathFilter(path string, filter string) bool {
+	return filter == "" || path == filter || strings.HasPrefix(path, filter+".") || strings.HasPrefix(path, filter+"[")
+}
+
+func FormatExplanations(explanations []Explanation, isSecret func(placeholder string) bool) string {
+	var text strings.Builder
+	for _, explanation := range explanations {
+		value := formatExplainedValue(explanation.Value)
+		if hasSecretResolution(explanation.Resolutions, isSecret) {
+			value = redactedValue
+		}
+		fmt.Fprintf(&text, "%s: %s\n", JoinPath(explanation.Path), value)
+		if explanation.Origin != nil {
+			fmt.Fprintf(&text, "  from %s\n", describeOrigin(*explanation.Origin))
+		}
+		writeResolutions(&text, explanation.Resolutions, "  ", isSecret)
+	}
+	return text.String()
+}
+
+func writeResolutions(text *strings.Builder, resolutions []Resolution, indent string, isSecret func(placeholder string) bool) {
+	for _, r := range resolutions {
+		value := formatExplainedValue(r.Value)
+		if hasSecretResolution([]Resolution{r}, isSecret) {
+			value = redactedValue
+		}
+		fmt.Fprintf(text, "%s%s = %s", indent, r.Placeholder, value)
+		if r.Text != r.Placeholder {
+			fmt.Fprintf(text, " in '%s'", r.Text)
+		}
+		fmt.Fprintf(text, " (%s)\n", r.Scope)
+		writeResolutions(text, r.Via, indent+"  ", isSecret)
+	}
+}
+
+func hasSecretResolution(resolutions []Resolution, isSecret func(placeholder string) bool) bool {
+	for _, r := range resolutions {
+		if isSecret(r.Placeholder) || hasSecretResolution(r.Via, isSecret) {
+			return true
+		}
+	}
+	return false
+}
+
+func describeOrigin(origin Resolution) string {
+	source := origin.Source
+	if source == "" {
+		source = "the component"
+	}
+	if origin.Configuration == "" {
+		return source
+	}
+	return fmt.Sprintf("%s, configuration '%s'", source, origin.Configuration)
+}
+
+func formatExplainedValue(value any) string {
+	switch {
+	case isMap(value):
+		return "{}"
+	case isSlice(value):
+		return "[]"
+	}
+	return fmt.Sprint(value)
+}
+
+func (r *Recipe) IsSecretPlaceholder(placeholder string) bool {
+	name, ok := strings.CutPrefix(placeholder, "$args.")
+	if !ok {
+		return false
+	}
+	arg, ok := r.Args[name]
+	return ok && arg.isSecret(name)
+}
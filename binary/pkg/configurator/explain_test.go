@@ -0,0 +1,126 @@
+package configurator
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainRecipe(t *testing.T) {
+	componentsDir := writeFiles(t, map[string]string{
+		"receivers/otlp.yml": `
+vars:
+  host: localhost
+  port: 4317
+configurations:
+  default:
+    content:
+      host: $vars.host
+      port: $vars.port
+      include_metadata: true
+  http:
+    vars:
+      port: 4318
+    content:
+      http_port: $vars.port
+`,
+		"exporters/otlp.yml": `
+vars:
+  endpoint:
+    required: true
+  token:
+    required: true
+configurations:
+  default:
+    content:
+      endpoint: $vars.endpoint
+      headers:
+        authorization: Bearer $vars.token
+      sending_queue: {}
+`,
+	})
+	t.Setenv("TEST_EXPLAIN_TOKEN", "secret-token")
+	recipe, err := ParseRecipe(strings.NewReader(`
+description: Explain test
+args:
+  endpoint:
+    description: The endpoint
+    default: collector:4317
+  token:
+    description: The token
+    env: TEST_EXPLAIN_TOKEN
+const:
+  host: 0.0.0.0
+components:
+  otlp-receiver:
+    source: receivers/otlp.yml
+    configurations: [ default, http ]
+    vars:
+      host: $const.host
+  otlp-exporter:
+    source: exporters/otlp.yml
+    vars:
+      endpoint: $args.endpoint
+      token: $args.token
+service:
+  pipelines:
+    traces:
+      receivers: [ $components.otlp-receiver ]
+      exporters: [ $components.otlp-exporter ]
+`))
+	assert.NoError(t, err)
+
+	var resolutions []Resolution
+	configuration, err := BuildRecipe(&recipe, BuildOptions{
+		Components: os.DirFS(componentsDir),
+		Trace: func(r Resolution) {
+			resolutions = append(resolutions, r)
+		},
+	})
+	assert.NoError(t, err)
+
+	explanations, err := Explain(configuration, resolutions, "")
+	assert.NoError(t, err)
+	assert.Equal(t, `exporters.otlp.endpoint: collector:4317
+  from exporters/otlp.yml, configuration 'default'
+  $vars.endpoint = collector:4317 (recipe component 'otlp-exporter' vars)
+    $args.endpoint = collector:4317 (recipe args, from its default value)
+exporters.otlp.headers.authorization: <redacted>
+  from exporters/otlp.yml, configuration 'default'
+  $vars.token = <redacted> in 'Bearer $vars.token' (recipe component 'otlp-exporter' vars)
+    $args.token = <redacted> (recipe args, from the TEST_EXPLAIN_TOKEN env var)
+exporters.otlp.sending_queue: {}
+  from exporters/otlp.yml, configuration 'default'
+receivers.otlp.host: 0.0.0.0
+  from receivers/otlp.yml, configuration 'default'
+  $vars.host = 0.0.0.0 (recipe component 'otlp-receiver' vars)
+    $const.host = 0.0.0.0 (recipe const)
+receivers.otlp.http_port: 4318
+  from receivers/otlp.yml, configuration 'http'
+  $vars.port = 4318 (configuration 'http' vars)
+receivers.otlp.include_metadata: true
+  from receivers/otlp.yml, configuration 'default'
+receivers.otlp.port: 4317
+  from receivers/otlp.yml, configuration 'default'
+  $vars.port = 4317 (component vars)
+service.pipelines.traces.exporters[0]: otlp
+  from the recipe service
+  $components.otlp-exporter = otlp (recipe components)
+service.pipelines.traces.receivers[0]: otlp
+  from the recipe service
+  $components.otlp-receiver = otlp (recipe components)
+`, FormatExplanations(explanations, recipe.IsSecretPlaceholder))
+
+	explanations, err = Explain(configuration, resolutions, "receivers.otlp.include_metadata")
+	assert.NoError(t, err)
+	assert.Len(t, explanations, 1)
+
+	explanations, err = Explain(configuration, resolutions, "service.pipelines")
+	assert.NoError(t, err)
+	assert.Len(t, explanations, 2)
+
+	_, err = Explain(configuration, resolutions, "receivers.otlp.unknown")
+	assert.EqualError(t, err, "no values found at 'receivers.otlp.unknown'")
+}
@@ -0,0 +1,220 @@
+package configurator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+const (
+	recipeTestsDirSuffix = ".tests"
+	expectedFileSuffix   = ".expected.yml"
+)
+
+type recipeTestCaseFile struct {
+	Description string
+	Args        map[string]string
+}
+
+type RecipeTestCase struct {
+	Name         string
+	RecipePath   string
+	CasePath     string
+	ExpectedPath string
+}
+
+type TestResult struct {
+	Name    string
+	Failure string
+	Updated bool
+}
+
+func (t RecipeTestCase) id() string {
+	return fmt.Sprintf("%s (%s)", t.RecipePath, t.Name)
+}
+
+// DiscoverRecipeTestCases finds the test cases of every recipe within dir, and the recipes that have none.
+func DiscoverRecipeTestCases(recipes fs.FS, dir string) ([]RecipeTestCase, []string, error) {
+	var testCases []RecipeTestCase
+	var uncovered []string
+	err := fs.WalkDir(recipes, dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if strings.HasSuffix(d.Name(), recipeTestsDirSuffix) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !yamlFileNamePattern.MatchString(d.Name()) {
+			return nil
+		}
+		recipeTestCases, err := FindRecipeTestCases(recipes, filePath)
+		if err != nil {
+			return err
+		}
+		if len(recipeTestCases) == 0 {
+			uncovered = append(uncovered, filePath)
+		}
+		testCases = append(testCases, recipeTestCases...)
+		return nil
+	})
+	return testCases, uncovered, err
+}
+
+// FindRecipeTestCases lists the test cases within the '<recipe>.tests' directory next to the recipe.
+func FindRecipeTestCases(recipes fs.FS, recipePath string) ([]RecipeTestCase, error) {
+	testsDir := strings.TrimSuffix(recipePath, path.Ext(recipePath)) + recipeTestsDirSuffix
+	entries, err := fs.ReadDir(recipes, testsDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var testCases []RecipeTestCase
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || path.Ext(name) != ".yml" || strings.HasSuffix(name, expectedFileSuffix) {
+			continue
+		}
+		caseName := strings.TrimSuffix(name, ".yml")
+		testCases = append(testCases, RecipeTestCase{
+			Name:         caseName,
+			RecipePath:   recipePath,
+			CasePath:     path.Join(testsDir, name),
+			ExpectedPath: path.Join(testsDir, caseName+expectedFileSuffix),
+		})
+	}
+	return testCases, nil
+}
+
+// RunRecipeTests builds the test cases, read from recipes, and compares them with their golden files. When writeGolden
+// is set, the golden files are written with it instead.
+func RunRecipeTests(recipes fs.FS, testCases []RecipeTestCase, components fs.FS, writeGolden func(path string, data []byte) error) []TestResult {
+	results := make([]TestResult, 0, len(testCases))
+	for _, testCase := range testCases {
+		result, err := runRecipeTestCase(recipes, testCase, components, writeGolden)
+		if err != nil {
+			result.Updated = false
+			result.Failure = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func runRecipeTestCase(recipes fs.FS, testCase RecipeTestCase, components fs.FS, writeGolden func(path string, data []byte) error) (TestResult, error) {
+	result := TestResult{Name: testCase.id()}
+	caseData, err := fs.ReadFile(recipes, testCase.CasePath)
+	if err != nil {
+		return result, err
+	}
+	var caseFile recipeTestCaseFile
+	if err = yaml.UnmarshalWithOptions(caseData, &caseFile, yaml.DisallowUnknownField()); err != nil {
+		return result, fmt.Errorf("invalid test case '%s': %w", testCase.CasePath, err)
+	}
+	recipe, err := LoadRecipe(recipes, testCase.RecipePath)
+	if err != nil {
+		return result, err
+	}
+	args := caseFile.Args
+	if args == nil {
+		args = make(map[string]string)
+	}
+	var unset []string
+	for _, name := range slices.Sorted(maps.Keys(recipe.Args)) {
+		if _, ok := args[name]; !ok && recipe.Args[name].Default == "" {
+			unset = append(unset, name)
+		}
+	}
+	if len(unset) > 0 {
+		return result, fmt.Errorf("test case '%s' must set the args without a default value: %s", testCase.CasePath, strings.Join(unset, ", "))
+	}
+	built, err := BuildRecipeYaml(&recipe, BuildOptions{
+		Args:       args,
+		Components: components,
+		IgnoreEnv:  true,
+		Warn:       func(string) {},
+	})
+	if err != nil {
+		return result, fmt.Errorf("could not build test case '%s': %w", testCase.CasePath, err)
+	}
+
+	if writeGolden != nil {
+		result.Updated = true
+		return result, writeGolden(testCase.ExpectedPath, built)
+	}
+	expectedData, err := fs.ReadFile(recipes, testCase.ExpectedPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		result.Failure = fmt.Sprintf("missing golden file '%s', run with -update to create it", testCase.ExpectedPath)
+		return result, nil
+	}
+	if err != nil {
+		return result, err
+	}
+	result.Failure, err = compareYaml(expectedData, built)
+	if result.Failure != "" && caseFile.Description != "" {
+		result.Failure = caseFile.Description + "\n\n" + result.Failure
+	}
+	return result, err
+}
+
+func compareYaml(expectedData []byte, actualData []byte) (string, error) {
+	var expected, actual any
+	if err := yaml.Unmarshal(expectedData, &expected); err != nil {
+		return "", err
+	}
+	if err := yaml.Unmarshal(actualData, &actual); err != nil {
+		return "", err
+	}
+	entries := Diff(expected, actual)
+	if len(entries) == 0 {
+		return "", nil
+	}
+	text, err := FormatDiff(entries, "text")
+	return string(text), err
+}
+
+func FormatTestResults(results []TestResult, uncovered []string) string {
+	var text strings.Builder
+	passed, failed, updated := 0, 0, 0
+	for _, result := range results {
+		switch {
+		case result.Updated:
+			updated++
+			fmt.Fprintf(&text, "UPDATED %s\n", result.Name)
+		case result.Failure != "":
+			failed++
+			fmt.Fprintf(&text, "FAIL    %s\n", result.Name)
+			for _, line := range strings.Split(strings.TrimSuffix(result.Failure, "\n"), "\n") {
+				fmt.Fprintf(&text, "  %s\n", line)
+			}
+		default:
+			passed++
+			fmt.Fprintf(&text, "PASS    %s\n", result.Name)
+		}
+	}
+	for _, path := range slices.Sorted(slices.Values(uncovered)) {
+		fmt.Fprintf(&text, "warning: '%s' has no test cases\n", path)
+	}
+	fmt.Fprintf(&text, "\n%d passed, %d failed", passed, failed)
+	if updated > 0 {
+		fmt.Fprintf(&text, ", %d updated", updated)
+	}
+	text.WriteString("\n")
+	return text.String()
+}
+
+func HasTestFailures(results []TestResult) bool {
+	return slices.ContainsFunc(results, func(result TestResult) bool {
+		return result.Failure != ""
+	})
+}
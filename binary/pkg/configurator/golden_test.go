@@ -0,0 +1,149 @@
+package configurator
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecipeGoldenFiles(t *testing.T) {
+	recipesFS := os.DirFS("../../../recipes")
+	testCases, uncovered, err := DiscoverRecipeTestCases(recipesFS, ".")
+	assert.NoError(t, err)
+	assert.Empty(t, uncovered, "every recipe needs at least one test case")
+	assert.NotEmpty(t, testCases)
+
+	for _, result := range RunRecipeTests(recipesFS, testCases, os.DirFS("../../../components"), nil) {
+		assert.Empty(t, result.Failure, result.Name)
+	}
+}
+
+func TestRunRecipeTests(t *testing.T) {
+	componentsDir := writeFiles(t, map[string]string{
+		"receivers/otlp.yml": `
+configurations:
+  default:
+    content: {}
+`,
+		"exporters/otlp.yml": `
+vars:
+  endpoint:
+    required: true
+configurations:
+  default:
+    content:
+      endpoint: $vars.endpoint
+`,
+	})
+	recipesDir := t.TempDir()
+	writeFile := func(path string, content string) {
+		path = filepath.Join(recipesDir, path)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	writeFile("otlp.yml", `
+description: Golden test
+args:
+  endpoint:
+    description: The endpoint
+    env: TEST_GOLDEN_ENDPOINT
+components:
+  otlp-receiver:
+    source: receivers/otlp.yml
+  otlp-exporter:
+    source: exporters/otlp.yml
+    vars:
+      endpoint: $args.endpoint
+service:
+  pipelines:
+    traces:
+      receivers: [ $components.otlp-receiver ]
+      exporters: [ $components.otlp-exporter ]
+`)
+	writeFile("otlp.tests/local.yml", `
+description: Exports to a local collector.
+args:
+  endpoint: localhost:4317
+`)
+	writeFile("untested.yml", "description: Untested\n")
+	writeFile("untested.yaml", "description: Untested\n")
+
+	recipesFS := os.DirFS(recipesDir)
+	writeGolden := func(path string, data []byte) error {
+		writeFile(path, string(data))
+		return nil
+	}
+
+	testCases, uncovered, err := DiscoverRecipeTestCases(recipesFS, ".")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"untested.yaml", "untested.yml"}, uncovered)
+	assert.Equal(t, []RecipeTestCase{{
+		Name:         "local",
+		RecipePath:   "otlp.yml",
+		CasePath:     "otlp.tests/local.yml",
+		ExpectedPath: "otlp.tests/local.expected.yml",
+	}}, testCases)
+	found, err := FindRecipeTestCases(recipesFS, "otlp.yml")
+	assert.NoError(t, err)
+	assert.Equal(t, testCases, found)
+
+	results := RunRecipeTests(recipesFS, testCases, os.DirFS(componentsDir), nil)
+	assert.Equal(t, "missing golden file 'otlp.tests/local.expected.yml', run with -update to create it", results[0].Failure)
+
+	results = RunRecipeTests(recipesFS, testCases, os.DirFS(componentsDir), writeGolden)
+	assert.Equal(t, []TestResult{{Name: testCases[0].id(), Updated: true}}, results)
+	expected, err := fs.ReadFile(recipesFS, testCases[0].ExpectedPath)
+	assert.NoError(t, err)
+	assert.Equal(t, `receivers:
+  otlp: {}
+exporters:
+  otlp:
+    endpoint: localhost:4317
+service:
+  pipelines:
+    traces:
+      receivers:
+      - otlp
+      exporters:
+      - otlp
+`, string(expected))
+
+	writeFile("otlp.tests/local.expected.yml", `
+service: { pipelines: { traces: { receivers: [ otlp ], exporters: [ otlp ] } } }
+receivers: { otlp: {} }
+exporters: { otlp: { endpoint: "localhost:4318" } }
+`)
+	results = RunRecipeTests(recipesFS, testCases, os.DirFS(componentsDir), nil)
+	assert.Equal(t, "Exports to a local collector.\n\n~ exporters.otlp.endpoint: localhost:4318 -> localhost:4317\n\n0 added, 0 removed, 1 changed, 0 reordered\n", results[0].Failure)
+	assert.True(t, HasTestFailures(results))
+
+	t.Setenv("TEST_GOLDEN_ENDPOINT", "localhost:4318")
+	results = RunRecipeTests(recipesFS, testCases, os.DirFS(componentsDir), nil)
+	assert.Contains(t, results[0].Failure, "~ exporters.otlp.endpoint: localhost:4318 -> localhost:4317")
+
+	writeFile("otlp.tests/local.yml", "args: {}\n")
+	results = RunRecipeTests(recipesFS, testCases, os.DirFS(componentsDir), nil)
+	assert.Contains(t, results[0].Failure, "test case 'otlp.tests/local.yml' must set the args without a default value: endpoint")
+
+	writeFile("otlp.tests/local.yml", "unknown: field\n")
+	results = RunRecipeTests(recipesFS, testCases, os.DirFS(componentsDir), nil)
+	assert.Contains(t, results[0].Failure, "invalid test case")
+}
+
+func TestFormatTestResults(t *testing.T) {
+	assert.Equal(t, `PASS    a.yml (default)
+FAIL    b.yml (default)
+  ~ x: 1 -> 2
+UPDATED c.yml (default)
+warning: 'd.yml' has no test cases
+
+1 passed, 1 failed, 1 updated
+`, FormatTestResults([]TestResult{
+		{Name: "a.yml (default)"},
+		{Name: "b.yml (default)", Failure: "~ x: 1 -> 2\n"},
+		{Name: "c.yml (default)", Updated: true},
+	}, []string{"d.yml"}))
+}
@@ -0,0 +1,214 @@
+package configurator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+const schemaURLPrefix = "file:///"
+
+var schemaMessages = message.NewPrinter(language.English)
+
+type SchemaError struct {
+	Path    []string
+	Message string
+}
+
+func (e SchemaError) Error() string {
+	if len(e.Path) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", JoinPath(e.Path), e.Message)
+}
+
+func loadComponentSchema(component *Component, componentsFS fs.FS, source string, kind string, typeName string, schemasFS fs.FS) (*jsonschema.Schema, error) {
+	switch schema := component.Schema.(type) {
+	case nil:
+	case map[string]any:
+		return compileSchema(componentsFS, source, schema)
+	case string:
+		schemaPath := path.Join(path.Dir(source), schema)
+		document, err := loadSchemaFile(componentsFS, schemaPath)
+		if err != nil {
+			return nil, err
+		}
+		return compileSchema(componentsFS, schemaPath, document)
+	default:
+		return nil, fmt.Errorf("invalid schema, must be either a path or an inline schema, got %v", getKind(schema))
+	}
+	if schemasFS == nil {
+		return nil, nil
+	}
+	schemaPath := path.Join(kind, typeName+".schema.json")
+	document, err := loadSchemaFile(schemasFS, schemaPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return compileSchema(schemasFS, schemaPath, document)
+}
+
+func loadSchemaFile(fsys fs.FS, path string) (any, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	var schema map[string]any
+	err = yaml.Unmarshal(data, &schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema file '%s': %w", path, err)
+	}
+	return toJSONValue(schema)
+}
+
+// compileSchema compiles the schema found at the given path of fsys. Refs to other schema files are resolved relative to
+// it, within fsys.
+func compileSchema(fsys fs.FS, path string, schema any) (*jsonschema.Schema, error) {
+	document, err := toJSONValue(schema)
+	if err != nil {
+		return nil, err
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.AssertFormat()
+	compiler.UseLoader(schemaLoader{fsys: fsys})
+	err = compiler.AddResource(schemaURLPrefix+path, document)
+	if err != nil {
+		return nil, err
+	}
+	return compiler.Compile(schemaURLPrefix + path)
+}
+
+type schemaLoader struct {
+	fsys fs.FS
+}
+
+func (l schemaLoader) Load(url string) (any, error) {
+	path, ok := strings.CutPrefix(url, schemaURLPrefix)
+	if !ok {
+		return nil, fmt.Errorf("unsupported schema ref '%s', only refs to schema files are supported", url)
+	}
+	return loadSchemaFile(l.fsys, path)
+}
+
+// toJSONValue converts a value parsed from YAML to the one the JSON decoder of the schema library would produce.
+func toJSONValue(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema.UnmarshalJSON(bytes.NewReader(data))
+}
+
+func validateComponentSchemas(config map[string]any, components map[string]*loadedComponent) error {
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(components)) {
+		component := components[key]
+		if component.Schema == nil {
+			continue
+		}
+		section, _ := config[component.Kind].(map[string]any)
+		schemaErrs, err := validateWithSchema(component.Schema, section[component.Name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("component '%s' could not be validated against its schema: %w", key, err))
+		}
+		for _, schemaErr := range schemaErrs {
+			schemaErr.Path = append([]string{component.Kind, component.Name}, schemaErr.Path...)
+			errs = append(errs, fmt.Errorf("component '%s' doesn't match its schema: %w", key, schemaErr))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateWithSchema(schema *jsonschema.Schema, value any) ([]SchemaError, error) {
+	instance, err := toJSONValue(value)
+	if err != nil {
+		return nil, err
+	}
+	err = schema.Validate(instance)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return nil, err
+	}
+	var errs []SchemaError
+	collectSchemaErrors(validationErr, instance, &errs)
+	slices.SortFunc(errs, func(a, b SchemaError) int {
+		return strings.Compare(a.Error(), b.Error())
+	})
+	return slices.CompactFunc(errs, func(a, b SchemaError) bool {
+		return a.Error() == b.Error()
+	}), nil
+}
+
+func collectSchemaErrors(err *jsonschema.ValidationError, instance any, errs *[]SchemaError) {
+	if len(err.Causes) == 0 {
+		*errs = append(*errs, SchemaError{
+			Path:    instancePath(instance, err.InstanceLocation),
+			Message: err.ErrorKind.LocalizedString(schemaMessages),
+		})
+	}
+	for _, cause := range err.Causes {
+		collectSchemaErrors(cause, instance, errs)
+	}
+}
+
+// instancePath converts a JSON pointer location to a path, with list indexes in the [<index>] form used by JoinPath.
+func instancePath(instance any, location []string) []string {
+	path := make([]string, 0, len(location))
+	for _, token := range location {
+		switch value := instance.(type) {
+		case []any:
+			path = append(path, "["+token+"]")
+			if index, err := strconv.Atoi(token); err == nil && index < len(value) {
+				instance = value[index]
+			}
+		case map[string]any:
+			path = append(path, token)
+			instance = value[token]
+		default:
+			path = append(path, token)
+		}
+	}
+	return path
+}
+
+func toFloat(value any) (float64, bool) {
+	if value == nil {
+		return 0, false
+	}
+	reflected := reflect.ValueOf(value)
+	switch {
+	case isFloat(value):
+		return reflected.Float(), true
+	case strings.HasPrefix(getKind(value).String(), "uint"):
+		return float64(reflected.Uint()), true
+	case isInteger(value):
+		return float64(reflected.Int()), true
+	}
+	return 0, false
+}
+
+func jsonEqual(a any, b any) bool {
+	aNumber, aIsNumber := toFloat(a)
+	bNumber, bIsNumber := toFloat(b)
+	if aIsNumber && bIsNumber {
+		return aNumber == bNumber
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,255 @@
+package configurator
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateWithSchema(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"size":    map[string]any{"type": "integer", "minimum": 0},
+			"ratio":   map[string]any{"type": "number", "exclusiveMaximum": 1},
+			"mode":    map[string]any{"enum": []any{"fast", "safe"}},
+			"timeout": map[string]any{"type": "string", "pattern": "^[0-9]+s$"},
+			"keys":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "maxItems": 2, "uniqueItems": true},
+			"tls":     map[string]any{"$ref": "#/$defs/tls"},
+			"name":    map[string]any{"type": "string", "minLength": 2, "maxLength": 3},
+			"host":    map[string]any{"format": "ipv4"},
+			"headers": map[string]any{"patternProperties": map[string]any{"^x-": map[string]any{"type": "string"}}},
+			"level":   map[string]any{"not": map[string]any{"const": "debug"}},
+		},
+		"required":             []any{"size"},
+		"additionalProperties": false,
+		"$defs": map[string]any{
+			"tls": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"insecure": map[string]any{"type": "boolean"},
+				},
+			},
+		},
+	}
+	for _, tc := range []struct {
+		testName       string
+		value          any
+		expectedErrors []string
+	}{
+		{
+			testName: "valid",
+			value: map[string]any{
+				"size":    uint64(10),
+				"ratio":   0.5,
+				"mode":    "fast",
+				"timeout": "1s",
+				"keys":    []any{"a", "b"},
+				"tls":     map[string]any{"insecure": true},
+				"name":    "日本語",
+				"host":    "127.0.0.1",
+				"headers": map[string]any{"x-key": "value"},
+				"level":   "info",
+			},
+		},
+		{
+			testName: "whole floats are integers",
+			value:    map[string]any{"size": 10.0},
+		},
+		{
+			testName:       "wrong root type",
+			value:          "text",
+			expectedErrors: []string{"got string, want object"},
+		},
+		{
+			testName: "invalid values",
+			value: map[string]any{
+				"size":    int64(-1),
+				"ratio":   uint64(1),
+				"mode":    "slow",
+				"timeout": "1m",
+				"keys":    []any{"a", 1, "a"},
+				"tls":     map[string]any{"insecure": "yes"},
+				"name":    "日本語語",
+				"host":    "localhost",
+				"headers": map[string]any{"x-key": 1},
+				"level":   "debug",
+				"other":   true,
+			},
+			expectedErrors: []string{
+				"additional properties 'other' not allowed",
+				"headers.x-key: got number, want string",
+				"host: 'localhost' is not valid ipv4: expected four decimals",
+				"keys: items at 0 and 2 are equal",
+				"keys: maxItems: got 3, want 2",
+				"keys[1]: got number, want string",
+				"level: 'not' failed",
+				"mode: value must be one of 'fast', 'safe'",
+				"name: maxLength: got 4, want 3",
+				"ratio: exclusiveMaximum: got 1, want 1",
+				"size: minimum: got -1, want 0",
+				"timeout: '1m' does not match pattern '^[0-9]+s$'",
+				"tls.insecure: got string, want boolean",
+			},
+		},
+		{
+			testName:       "missing required",
+			value:          map[string]any{},
+			expectedErrors: []string{"missing property 'size'"},
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			compiled, err := compileSchema(nil, "schema.json", schema)
+			assert.NoError(t, err)
+			schemaErrs, err := validateWithSchema(compiled, tc.value)
+			assert.NoError(t, err)
+			var messages []string
+			for _, err := range schemaErrs {
+				messages = append(messages, err.Error())
+			}
+			assert.Equal(t, tc.expectedErrors, messages)
+		})
+	}
+}
+
+func TestBuildRecipeWithComponentSchemas(t *testing.T) {
+	componentsDir := writeFiles(t, map[string]string{
+		"receivers/otlp.yml": `
+schema:
+  type: object
+  required: [ endpoint ]
+vars:
+  endpoint:
+    required: true
+configurations:
+  default:
+    content:
+      endpoint: $vars.endpoint
+`,
+		"processors/batch.yml": `
+schema: batch.schema.json
+vars:
+  size:
+    type: int
+configurations:
+  default:
+    content:
+      send_batch_size: $vars.size
+`,
+		"processors/batch.schema.json": `{
+  "type": "object",
+  "properties": {
+    "send_batch_size": { "type": "integer", "minimum": 1 }
+  },
+  "additionalProperties": false
+}`,
+		"exporters/debug.yml": `
+vars:
+  verbosity: basic
+configurations:
+  default:
+    content:
+      verbosity: $vars.verbosity
+`,
+		"schemas/exporters/debug.schema.json": `{
+  "properties": {
+    "verbosity": { "enum": [ "basic", "normal", "detailed" ] }
+  }
+}`,
+	})
+	recipeYaml := `
+description: Schemas test
+args:
+  unused:
+    description: Unused arg
+components:
+  otlp:
+    source: receivers/otlp.yml
+    vars:
+      endpoint: localhost:4317
+  batch:
+    source: processors/batch.yml
+    vars:
+      size: 100
+  debug:
+    source: exporters/debug.yml
+    vars:
+      verbosity: detailed
+service:
+  pipelines:
+    traces:
+      receivers: [ $components.otlp ]
+      processors: [ $components.batch ]
+      exporters: [ $components.debug ]
+`
+	params := BuildOptions{
+		Args:       map[string]string{"unused": "value"},
+		Components: os.DirFS(componentsDir),
+		Schemas:    os.DirFS(componentsDir + "/schemas"),
+	}
+
+	recipe, err := ParseRecipe(strings.NewReader(recipeYaml))
+	assert.NoError(t, err)
+	_, err = BuildRecipe(&recipe, params)
+	assert.NoError(t, err)
+
+	recipe, err = ParseRecipe(strings.NewReader(strings.NewReplacer(
+		"size: 100", "size: 0",
+		"verbosity: detailed", "verbosity: verbose",
+	).Replace(recipeYaml)))
+	assert.NoError(t, err)
+	_, err = BuildRecipe(&recipe, params)
+	assert.EqualError(t, err, strings.Join([]string{
+		"component 'batch' doesn't match its schema: processors.batch.send_batch_size: minimum: got 0, want 1",
+		"component 'debug' doesn't match its schema: exporters.debug.verbosity: value must be one of 'basic', 'normal', 'detailed'",
+	}, "\n"))
+}
+
+func TestCompileSchema(t *testing.T) {
+	schemasFS := fstest.MapFS{
+		"processors/common.schema.json": {Data: []byte(`{ "$defs": { "size": { "type": "integer", "minimum": 1 } } }`)},
+	}
+	for _, tc := range []struct {
+		testName       string
+		schema         map[string]any
+		value          any
+		expectedErrors []string
+		expectedError  string
+	}{
+		{
+			testName:       "ref to another schema file",
+			schema:         map[string]any{"properties": map[string]any{"size": map[string]any{"$ref": "common.schema.json#/$defs/size"}}},
+			value:          map[string]any{"size": uint64(0)},
+			expectedErrors: []string{"size: minimum: got 0, want 1"},
+		},
+		{
+			testName:      "invalid keyword value",
+			schema:        map[string]any{"minLength": "two"},
+			expectedError: "at '/minLength': got string, want integer",
+		},
+		{
+			testName:      "ref to a missing schema file",
+			schema:        map[string]any{"$ref": "missing.schema.json"},
+			expectedError: "open processors/missing.schema.json: file does not exist",
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			compiled, err := compileSchema(schemasFS, "processors/batch.schema.json", tc.schema)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			schemaErrs, err := validateWithSchema(compiled, tc.value)
+			assert.NoError(t, err)
+			var messages []string
+			for _, err := range schemaErrs {
+				messages = append(messages, err.Error())
+			}
+			assert.Equal(t, tc.expectedErrors, messages)
+		})
+	}
+}
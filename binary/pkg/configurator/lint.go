@@ -0,0 +1,354 @@
+package configurator
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+type LintSeverity string
+
+const (
+	LintError   LintSeverity = "error"
+	LintWarning LintSeverity = "warning"
+)
+
+type LintOptions struct {
+	Disable []string
+}
+
+type LintFinding struct {
+	RuleId   string       `json:"rule_id"`
+	Severity LintSeverity `json:"severity"`
+	Message  string       `json:"message"`
+	Path     string       `json:"path,omitempty"`
+}
+
+// LintContext is what lint rules check: the recipe, its built configuration and the args that were replaced by
+// placeholder values to build it.
+type LintContext struct {
+	Recipe         *Recipe
+	Config         map[string]any
+	UnprovidedArgs []string
+}
+
+// LintRule checks a recipe. The findings it returns get the rule's ID and severity.
+type LintRule struct {
+	Id          string
+	Description string
+	Severity    LintSeverity
+	Check       func(ctx *LintContext) []LintFinding
+}
+
+// LintParams configures Lint. Rules are run along with the default ones, and replace the default rules with the same
+// ID.
+type LintParams struct {
+	UnprovidedArgs []string
+	Rules          []LintRule
+}
+
+var lintRules = []LintRule{
+	{
+		Id:          "unused-component",
+		Description: "Components should be referenced from the service or from other components.",
+		Severity:    LintWarning,
+		Check: func(ctx *LintContext) []LintFinding {
+			return findingsForKeys(findUnused(ctx.Recipe).Components, "components", "component '%s' is defined but never referenced from the service or other components")
+		},
+	},
+	{
+		Id:          "unused-arg",
+		Description: "Args should be used via $args.",
+		Severity:    LintWarning,
+		Check: func(ctx *LintContext) []LintFinding {
+			return findingsForKeys(findUnused(ctx.Recipe).Args, "args", "arg '%s' is defined but never used")
+		},
+	},
+	{
+		Id:          "unused-const",
+		Description: "Consts should be used via $const.",
+		Severity:    LintWarning,
+		Check: func(ctx *LintContext) []LintFinding {
+			return findingsForKeys(findUnused(ctx.Recipe).Consts, "const", "const '%s' is defined but never used")
+		},
+	},
+	{
+		Id:          "unprovided-arg",
+		Description: "Args should be provided, so that the configuration is checked with their actual values.",
+		Severity:    LintWarning,
+		Check: func(ctx *LintContext) []LintFinding {
+			return findingsForKeys(ctx.UnprovidedArgs, "args", "arg '%s' isn't provided, the configuration was checked with a placeholder value")
+		},
+	},
+	{
+		Id:          "memory-limiter-first",
+		Description: "The memory_limiter processor should be the first processor of a pipeline.",
+		Severity:    LintWarning,
+		Check: func(ctx *LintContext) []LintFinding {
+			return checkProcessorPositions(ctx.Config, "memory_limiter", func(index int, total int) bool {
+				return index == 0
+			}, "the memory_limiter processor '%s' should be the first processor of the pipeline")
+		},
+	},
+	{
+		Id:          "batch-near-end",
+		Description: "The batch processor should be one of the last two processors of a pipeline.",
+		Severity:    LintWarning,
+		Check: func(ctx *LintContext) []LintFinding {
+			return checkProcessorPositions(ctx.Config, "batch", func(index int, total int) bool {
+				return index >= total-2
+			}, "the batch processor '%s' should be placed near the end of the pipeline")
+		},
+	},
+	{
+		Id:          "no-debug-exporter",
+		Description: "Production recipes shouldn't use the debug exporter.",
+		Severity:    LintWarning,
+		Check: func(ctx *LintContext) []LintFinding {
+			var findings []LintFinding
+			exporters, _ := ctx.Config["exporters"].(map[string]any)
+			for _, name := range slices.Sorted(maps.Keys(exporters)) {
+				if componentTypeOf(name) == "debug" {
+					findings = append(findings, LintFinding{
+						Message: fmt.Sprintf("the debug exporter '%s' shouldn't be used in production", name),
+						Path:    JoinPath([]string{"exporters", name}),
+					})
+				}
+			}
+			return findings
+		},
+	},
+	{
+		Id:          "no-insecure-tls",
+		Description: "TLS certificate verification shouldn't be disabled.",
+		Severity:    LintError,
+		Check: func(ctx *LintContext) []LintFinding {
+			var findings []LintFinding
+			walkLeaves(ctx.Config, []string{}, func(path []string, value any) {
+				if len(path) < 2 || value != true || path[len(path)-2] != "tls" {
+					return
+				}
+				if key := path[len(path)-1]; key == "insecure" || key == "insecure_skip_verify" {
+					findings = append(findings, LintFinding{
+						Message: fmt.Sprintf("'%s' disables TLS", key),
+						Path:    JoinPath(path),
+					})
+				}
+			})
+			return findings
+		},
+	},
+	{
+		Id:          "no-wildcard-bind",
+		Description: "Endpoints shouldn't listen on all network interfaces.",
+		Severity:    LintWarning,
+		Check: func(ctx *LintContext) []LintFinding {
+			var findings []LintFinding
+			for _, section := range []string{"receivers", "extensions"} {
+				walkLeaves(ctx.Config[section], []string{section}, func(path []string, value any) {
+					text, ok := value.(string)
+					if !ok || path[len(path)-1] != "endpoint" {
+						return
+					}
+					if strings.HasPrefix(text, "0.0.0.0:") || strings.HasPrefix(text, "[::]:") || strings.HasPrefix(text, ":") {
+						findings = append(findings, LintFinding{
+							Message: fmt.Sprintf("'%s' listens on all network interfaces", text),
+							Path:    JoinPath(path),
+						})
+					}
+				})
+			}
+			return findings
+		},
+	},
+}
+
+// LintRules returns the rules Lint runs for the recipe: the default ones and the ones of the params, except the ones
+// the recipe disables.
+func LintRules(recipe *Recipe, params LintParams) ([]LintRule, error) {
+	rules := slices.Clone(lintRules)
+	for _, rule := range params.Rules {
+		if i := slices.IndexFunc(rules, func(other LintRule) bool { return other.Id == rule.Id }); i >= 0 {
+			rules[i] = rule
+		} else {
+			rules = append(rules, rule)
+		}
+	}
+	for _, id := range recipe.Lint.Disable {
+		if !slices.ContainsFunc(rules, func(rule LintRule) bool { return rule.Id == id }) {
+			return nil, fmt.Errorf("unknown lint rule to disable: '%s'", id)
+		}
+	}
+	return slices.DeleteFunc(rules, func(rule LintRule) bool {
+		return slices.Contains(recipe.Lint.Disable, rule.Id)
+	}), nil
+}
+
+// Lint checks the recipe and its built configuration against the rules returned by LintRules.
+func Lint(recipe *Recipe, config map[string]any, params LintParams) ([]LintFinding, error) {
+	rules, err := LintRules(recipe, params)
+	if err != nil {
+		return nil, err
+	}
+	ctx := &LintContext{
+		Recipe:         recipe,
+		Config:         config,
+		UnprovidedArgs: params.UnprovidedArgs,
+	}
+	var findings []LintFinding
+	for _, rule := range rules {
+		for _, finding := range rule.Check(ctx) {
+			finding.RuleId = rule.Id
+			finding.Severity = rule.Severity
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
+
+func HasLintErrors(findings []LintFinding) bool {
+	return slices.ContainsFunc(findings, func(finding LintFinding) bool {
+		return finding.Severity == LintError
+	})
+}
+
+// FormatLintFindings formats the findings of the rules. The rules are only listed by the sarif format.
+func FormatLintFindings(findings []LintFinding, rules []LintRule, format string, recipePath string) ([]byte, error) {
+	switch format {
+	case "text":
+		var text strings.Builder
+		for _, finding := range findings {
+			fmt.Fprintf(&text, "%s[%s] ", finding.Severity, finding.RuleId)
+			if finding.Path != "" {
+				fmt.Fprintf(&text, "%s: ", finding.Path)
+			}
+			fmt.Fprintln(&text, finding.Message)
+		}
+		return []byte(text.String()), nil
+	case "json":
+		if findings == nil {
+			findings = []LintFinding{}
+		}
+		return marshalJsonLine(findings)
+	case "sarif":
+		return marshalJsonLine(toSarif(findings, rules, recipePath))
+	}
+	return nil, fmt.Errorf("unknown output format '%s', must be one of: text, json, sarif", format)
+}
+
+func marshalJsonLine(value any) ([]byte, error) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func toSarif(findings []LintFinding, rules []LintRule, recipePath string) map[string]any {
+	sarifRules := []any{}
+	for _, rule := range rules {
+		sarifRules = append(sarifRules, map[string]any{
+			"id":                   rule.Id,
+			"shortDescription":     map[string]any{"text": rule.Description},
+			"defaultConfiguration": map[string]any{"level": string(rule.Severity)},
+		})
+	}
+	results := []any{}
+	for _, finding := range findings {
+		location := map[string]any{
+			"physicalLocation": map[string]any{
+				"artifactLocation": map[string]any{"uri": recipePath},
+			},
+		}
+		if finding.Path != "" {
+			location["logicalLocations"] = []any{
+				map[string]any{"fullyQualifiedName": finding.Path},
+			}
+		}
+		results = append(results, map[string]any{
+			"ruleId":    finding.RuleId,
+			"level":     string(finding.Severity),
+			"message":   map[string]any{"text": finding.Message},
+			"locations": []any{location},
+		})
+	}
+	return map[string]any{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []any{
+			map[string]any{
+				"tool": map[string]any{
+					"driver": map[string]any{
+						"name":  "edot-collector-configurator",
+						"rules": sarifRules,
+					},
+				},
+				"results": results,
+			},
+		},
+	}
+}
+
+func checkProcessorPositions(config map[string]any, processorType string, isValidPosition func(index int, total int) bool, message string) []LintFinding {
+	var findings []LintFinding
+	service, _ := config["service"].(map[string]any)
+	pipelines, _ := service["pipelines"].(map[string]any)
+	for _, pipelineId := range slices.Sorted(maps.Keys(pipelines)) {
+		pipeline, _ := pipelines[pipelineId].(map[string]any)
+		processors, _ := pipeline["processors"].([]any)
+		for i, processor := range processors {
+			name := fmt.Sprint(processor)
+			if componentTypeOf(name) == processorType && !isValidPosition(i, len(processors)) {
+				findings = append(findings, LintFinding{
+					Message: fmt.Sprintf(message, name),
+					Path:    JoinPath([]string{"service", "pipelines", pipelineId, "processors", fmt.Sprintf("[%d]", i)}),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func findingsForKeys(keys []string, section string, message string) []LintFinding {
+	var findings []LintFinding
+	for _, k := range keys {
+		findings = append(findings, LintFinding{
+			Message: fmt.Sprintf(message, k),
+			Path:    JoinPath([]string{section, k}),
+		})
+	}
+	return findings
+}
+
+func walkLeaves(value any, path []string, visit func(path []string, value any)) {
+	walk(value, path, false, visit)
+}
+
+// walkValues is walkLeaves, but also visits empty maps and lists, which are values of their own in a configuration,
+// e.g. `debug: {}`.
+func walkValues(value any, path []string, visit func(path []string, value any)) {
+	walk(value, path, true, visit)
+}
+
+func walk(value any, path []string, visitEmpty bool, visit func(path []string, value any)) {
+	switch {
+	case isMap(value) && (!visitEmpty || len(value.(map[string]any)) > 0):
+		mapValue := value.(map[string]any)
+		for _, k := range slices.Sorted(maps.Keys(mapValue)) {
+			walk(mapValue[k], append(slices.Clone(path), k), visitEmpty, visit)
+		}
+	case isSlice(value) && (!visitEmpty || len(value.([]any)) > 0):
+		for i, item := range value.([]any) {
+			walk(item, append(slices.Clone(path), fmt.Sprintf("[%d]", i)), visitEmpty, visit)
+		}
+	default:
+		visit(path, value)
+	}
+}
+
+func componentTypeOf(name string) string {
+	typeName, _, _ := strings.Cut(name, "/")
+	return typeName
+}
@@ -0,0 +1,189 @@
+package configurator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var lintedRecipe = `
+description: Recipe to lint
+args:
+  unused_arg:
+    description: Never used
+components:
+  otlp:
+    source: receivers/otlp.yml
+service: {}
+`
+
+var lintedConfiguration = map[string]any{
+	"receivers": map[string]any{
+		"otlp": map[string]any{
+			"protocols": map[string]any{
+				"grpc": map[string]any{"endpoint": "0.0.0.0:4317"},
+				"http": map[string]any{"endpoint": "localhost:4318"},
+			},
+		},
+	},
+	"exporters": map[string]any{
+		"debug/verbose": map[string]any{},
+		"elasticsearch": map[string]any{
+			"tls": map[string]any{"insecure": true},
+		},
+	},
+	"service": map[string]any{
+		"pipelines": map[string]any{
+			"traces": map[string]any{
+				"processors": []any{"batch", "memory_limiter", "transform", "elasticapm"},
+			},
+			"logs": map[string]any{
+				"processors": []any{"memory_limiter", "transform", "batch"},
+			},
+		},
+	},
+}
+
+func TestLintConfiguration(t *testing.T) {
+	recipe, err := ParseRecipe(strings.NewReader(lintedRecipe))
+	assert.NoError(t, err)
+	findings, err := Lint(&recipe, lintedConfiguration, LintParams{UnprovidedArgs: []string{"unused_arg"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []LintFinding{
+		{RuleId: "unused-component", Severity: LintWarning, Message: "component 'otlp' is defined but never referenced from the service or other components", Path: "components.otlp"},
+		{RuleId: "unused-arg", Severity: LintWarning, Message: "arg 'unused_arg' is defined but never used", Path: "args.unused_arg"},
+		{RuleId: "unprovided-arg", Severity: LintWarning, Message: "arg 'unused_arg' isn't provided, the configuration was checked with a placeholder value", Path: "args.unused_arg"},
+		{RuleId: "memory-limiter-first", Severity: LintWarning, Message: "the memory_limiter processor 'memory_limiter' should be the first processor of the pipeline", Path: "service.pipelines.traces.processors[1]"},
+		{RuleId: "batch-near-end", Severity: LintWarning, Message: "the batch processor 'batch' should be placed near the end of the pipeline", Path: "service.pipelines.traces.processors[0]"},
+		{RuleId: "no-debug-exporter", Severity: LintWarning, Message: "the debug exporter 'debug/verbose' shouldn't be used in production", Path: "exporters.debug/verbose"},
+		{RuleId: "no-insecure-tls", Severity: LintError, Message: "'insecure' disables TLS", Path: "exporters.elasticsearch.tls.insecure"},
+		{RuleId: "no-wildcard-bind", Severity: LintWarning, Message: "'0.0.0.0:4317' listens on all network interfaces", Path: "receivers.otlp.protocols.grpc.endpoint"},
+	}, findings)
+	assert.True(t, HasLintErrors(findings))
+
+	recipe.Lint.Disable = []string{"no-insecure-tls", "unused-component", "unused-arg", "unprovided-arg", "no-wildcard-bind", "no-debug-exporter"}
+	findings, err = Lint(&recipe, lintedConfiguration, LintParams{UnprovidedArgs: []string{"unused_arg"}})
+	assert.NoError(t, err)
+	assert.Len(t, findings, 2)
+	assert.False(t, HasLintErrors(findings))
+
+	recipe.Lint.Disable = []string{"no-such-rule"}
+	_, err = Lint(&recipe, lintedConfiguration, LintParams{})
+	assert.EqualError(t, err, "unknown lint rule to disable: 'no-such-rule'")
+}
+
+func TestLintWithCustomRules(t *testing.T) {
+	recipe, err := ParseRecipe(strings.NewReader(lintedRecipe))
+	assert.NoError(t, err)
+	recipe.Lint.Disable = []string{"unused-component", "memory-limiter-first", "batch-near-end", "no-debug-exporter", "no-wildcard-bind", "team-owner"}
+	params := LintParams{
+		Rules: []LintRule{
+			{
+				Id:          "no-insecure-tls",
+				Description: "Replaced rule.",
+				Severity:    LintWarning,
+				Check: func(ctx *LintContext) []LintFinding {
+					return []LintFinding{{Message: "replaced"}}
+				},
+			},
+			{
+				Id:          "team-owner",
+				Description: "Disabled rule.",
+				Severity:    LintError,
+				Check: func(ctx *LintContext) []LintFinding {
+					return []LintFinding{{Message: "disabled"}}
+				},
+			},
+			{
+				Id:          "description-prefix",
+				Description: "Recipe descriptions should start with the team name.",
+				Severity:    LintError,
+				Check: func(ctx *LintContext) []LintFinding {
+					if strings.HasPrefix(ctx.Recipe.Description, "[") {
+						return nil
+					}
+					return []LintFinding{{Message: "the description doesn't start with the team name", Path: "description"}}
+				},
+			},
+		},
+	}
+
+	rules, err := LintRules(&recipe, params)
+	assert.NoError(t, err)
+	var ids []string
+	for _, rule := range rules {
+		ids = append(ids, rule.Id)
+	}
+	assert.Equal(t, []string{"unused-arg", "unused-const", "unprovided-arg", "no-insecure-tls", "description-prefix"}, ids)
+
+	findings, err := Lint(&recipe, lintedConfiguration, params)
+	assert.NoError(t, err)
+	assert.Equal(t, []LintFinding{
+		{RuleId: "unused-arg", Severity: LintWarning, Message: "arg 'unused_arg' is defined but never used", Path: "args.unused_arg"},
+		{RuleId: "no-insecure-tls", Severity: LintWarning, Message: "replaced"},
+		{RuleId: "description-prefix", Severity: LintError, Message: "the description doesn't start with the team name", Path: "description"},
+	}, findings)
+
+	data, err := FormatLintFindings(findings, rules, "sarif", "recipe.yml")
+	assert.NoError(t, err)
+	var sarif struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						Id string
+					}
+				}
+			}
+		}
+	}
+	assert.NoError(t, json.Unmarshal(data, &sarif))
+	var sarifIds []string
+	for _, rule := range sarif.Runs[0].Tool.Driver.Rules {
+		sarifIds = append(sarifIds, rule.Id)
+	}
+	assert.Equal(t, ids, sarifIds)
+}
+
+func TestFormatLintFindings(t *testing.T) {
+	findings := []LintFinding{
+		{RuleId: "no-insecure-tls", Severity: LintError, Message: "'insecure' disables TLS", Path: "exporters.elasticsearch.tls.insecure"},
+	}
+
+	text, err := FormatLintFindings(findings, nil, "text", "recipe.yml")
+	assert.NoError(t, err)
+	assert.Equal(t, "error[no-insecure-tls] exporters.elasticsearch.tls.insecure: 'insecure' disables TLS\n", string(text))
+
+	data, err := FormatLintFindings(nil, nil, "json", "recipe.yml")
+	assert.NoError(t, err)
+	assert.Equal(t, "[]\n", string(data))
+
+	data, err = FormatLintFindings(findings, nil, "sarif", "recipe.yml")
+	assert.NoError(t, err)
+	var sarif struct {
+		Version string
+		Runs    []struct {
+			Results []struct {
+				RuleId    string
+				Level     string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							Uri string
+						}
+					}
+				}
+			}
+		}
+	}
+	assert.NoError(t, json.Unmarshal(data, &sarif))
+	assert.Equal(t, "2.1.0", sarif.Version)
+	assert.Equal(t, "no-insecure-tls", sarif.Runs[0].Results[0].RuleId)
+	assert.Equal(t, "error", sarif.Runs[0].Results[0].Level)
+	assert.Equal(t, "recipe.yml", sarif.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.Uri)
+
+	_, err = FormatLintFindings(findings, nil, "xml", "recipe.yml")
+	assert.EqualError(t, err, "unknown output format 'xml', must be one of: text, json, sarif")
+}
@@ -0,0 +1,122 @@
+package configurator
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var versionPattern = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:[-+].*)?$`)
+
+type Metadata struct {
+	Type         string
+	Kind         string `validate:"omitempty,oneof=receiver processor exporter extension connector"`
+	Description  string
+	Stability    string   `validate:"omitempty,oneof=development alpha beta stable deprecated unmaintained"`
+	MinVersion   string   `yaml:"min_version" validate:"omitempty,version"`
+	DeprecatedIn string   `yaml:"deprecated_in" validate:"omitempty,version"`
+	Signals      []string `validate:"dive,oneof=traces metrics logs profiles"`
+}
+
+func (m Metadata) checkCollectorVersion(collectorVersion string) ([]string, error) {
+	var warnings []string
+	if collectorVersion == "" {
+		return warnings, nil
+	}
+	if m.MinVersion != "" {
+		cmp, err := compareVersions(collectorVersion, m.MinVersion)
+		if err != nil {
+			return nil, err
+		}
+		if cmp < 0 {
+			warnings = append(warnings, fmt.Sprintf("requires EDOT Collector %s or later, but the recipe targets %s", m.MinVersion, collectorVersion))
+		}
+	}
+	if m.DeprecatedIn != "" {
+		cmp, err := compareVersions(collectorVersion, m.DeprecatedIn)
+		if err != nil {
+			return nil, err
+		}
+		if cmp >= 0 {
+			warnings = append(warnings, fmt.Sprintf("is deprecated since EDOT Collector %s, and the recipe targets %s", m.DeprecatedIn, collectorVersion))
+		}
+	}
+	return warnings, nil
+}
+
+var componentKinds = []string{"receiver", "processor", "exporter", "extension", "connector"}
+
+func (m Metadata) KindAndType(source string) (string, string) {
+	kind := filepath.Base(filepath.Dir(source))
+	if m.Kind != "" {
+		kind = m.Kind + "s"
+	} else if topDir, _, found := strings.Cut(filepath.ToSlash(source), "/"); found && slices.Contains(componentKinds, strings.TrimSuffix(topDir, "s")) {
+		kind = topDir
+	}
+	typeName := m.Type
+	if typeName == "" {
+		match := yamlFileNamePattern.FindStringSubmatch(filepath.Base(source))
+		if match != nil {
+			typeName = match[1]
+		}
+	}
+	return kind, typeName
+}
+
+func (m Metadata) Summary() string {
+	var details []string
+	if m.Stability != "" {
+		details = append(details, m.Stability)
+	}
+	if len(m.Signals) > 0 {
+		details = append(details, strings.Join(m.Signals, ", "))
+	}
+	if m.MinVersion != "" {
+		details = append(details, "since "+m.MinVersion)
+	}
+	if m.DeprecatedIn != "" {
+		details = append(details, "deprecated in "+m.DeprecatedIn)
+	}
+	if len(details) == 0 {
+		return m.Description
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s [%s]", m.Description, strings.Join(details, "; ")))
+}
+
+func compareVersions(a string, b string) (int, error) {
+	aParts, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	bParts, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range aParts {
+		if aParts[i] != bParts[i] {
+			if aParts[i] < bParts[i] {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func parseVersion(version string) ([3]int, error) {
+	var parts [3]int
+	match := versionPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if match == nil {
+		return parts, fmt.Errorf("invalid version: '%s'", version)
+	}
+	for i, part := range match[1:] {
+		if part == "" {
+			continue
+		}
+		parts[i], _ = strconv.Atoi(part)
+	}
+	return parts, nil
+}
@@ -0,0 +1,85 @@
+package configurator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var componentWithMetadata = `
+metadata:
+  type: otlp
+  kind: receiver
+  description: Receives OTLP data
+  stability: beta
+  min_version: 9.1.0
+  deprecated_in: v9.4
+  signals: [ traces, logs ]
+configurations:
+  default:
+    content: {}
+`
+
+func TestParseComponentMetadata(t *testing.T) {
+	component, err := ParseComponent(strings.NewReader(componentWithMetadata))
+	assert.NoError(t, err)
+	assert.Equal(t, Metadata{
+		Type:         "otlp",
+		Kind:         "receiver",
+		Description:  "Receives OTLP data",
+		Stability:    "beta",
+		MinVersion:   "9.1.0",
+		DeprecatedIn: "v9.4",
+		Signals:      []string{"traces", "logs"},
+	}, component.Metadata)
+
+	_, err = ParseComponent(strings.NewReader(`
+metadata:
+  kind: receivers
+configurations:
+  default:
+    content: {}
+`))
+	assert.ErrorContains(t, err, "Field validation for 'Kind' failed on the 'oneof' tag")
+}
+
+func TestCheckCollectorVersion(t *testing.T) {
+	metadata := Metadata{
+		MinVersion:   "9.1.0",
+		DeprecatedIn: "9.4",
+	}
+	for _, tc := range []struct {
+		testName         string
+		collectorVersion string
+		expectedWarnings []string
+	}{
+		{
+			testName:         "no targeted version",
+			collectorVersion: "",
+		},
+		{
+			testName:         "supported version",
+			collectorVersion: "v9.2.3",
+		},
+		{
+			testName:         "older version",
+			collectorVersion: "9.0.8",
+			expectedWarnings: []string{"requires EDOT Collector 9.1.0 or later, but the recipe targets 9.0.8"},
+		},
+		{
+			testName:         "deprecated version",
+			collectorVersion: "9.4.0-SNAPSHOT",
+			expectedWarnings: []string{"is deprecated since EDOT Collector 9.4, and the recipe targets 9.4.0-SNAPSHOT"},
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			warnings, err := metadata.checkCollectorVersion(tc.collectorVersion)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedWarnings, warnings)
+		})
+	}
+
+	_, err := metadata.checkCollectorVersion("latest")
+	assert.EqualError(t, err, "invalid version: 'latest'")
+}
@@ -0,0 +1,129 @@
+package configurator
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+var (
+	sectionsOrder     = []string{"extensions", "receivers", "processors", "connectors", "exporters", "service"}
+	serviceKeysOrder  = []string{"extensions", "pipelines", "telemetry"}
+	pipelineKeysOrder = []string{"receivers", "processors", "exporters"}
+)
+
+type outputOrder map[string][]string
+
+func (r *Recipe) outputOrder(components map[string]*loadedComponent) outputOrder {
+	order := make(outputOrder)
+	for _, k := range r.declaredComponentKeys() {
+		if component, ok := components[k]; ok {
+			order[component.Kind] = append(order[component.Kind], component.Name)
+		}
+	}
+	return order
+}
+
+func marshalConfiguration(configuration map[string]any, order outputOrder, comments yaml.CommentMap) ([]byte, error) {
+	if len(comments) == 0 {
+		return yaml.Marshal(orderConfiguration(configuration, order))
+	}
+	return yaml.MarshalWithOptions(orderConfiguration(configuration, order), yaml.WithComment(comments))
+}
+
+func orderConfiguration(configuration map[string]any, order outputOrder) yaml.MapSlice {
+	var ordered yaml.MapSlice
+	for _, section := range orderedKeys(configuration, sectionsOrder) {
+		value := configuration[section]
+		sectionMap, ok := value.(map[string]any)
+		switch {
+		case !ok:
+			ordered = append(ordered, yaml.MapItem{Key: section, Value: orderValue(value)})
+		case section == "service":
+			ordered = append(ordered, yaml.MapItem{Key: section, Value: orderService(sectionMap)})
+		default:
+			ordered = append(ordered, yaml.MapItem{Key: section, Value: orderMap(sectionMap, order[section])})
+		}
+	}
+	return ordered
+}
+
+func orderService(service map[string]any) yaml.MapSlice {
+	var ordered yaml.MapSlice
+	for _, k := range orderedKeys(service, serviceKeysOrder) {
+		pipelines, ok := service[k].(map[string]any)
+		if k != "pipelines" || !ok {
+			ordered = append(ordered, yaml.MapItem{Key: k, Value: orderValue(service[k])})
+			continue
+		}
+		var orderedPipelines yaml.MapSlice
+		for _, pipelineId := range orderedPipelineIds(pipelines) {
+			pipeline, ok := pipelines[pipelineId].(map[string]any)
+			if !ok {
+				orderedPipelines = append(orderedPipelines, yaml.MapItem{Key: pipelineId, Value: orderValue(pipelines[pipelineId])})
+				continue
+			}
+			orderedPipelines = append(orderedPipelines, yaml.MapItem{Key: pipelineId, Value: orderMap(pipeline, pipelineKeysOrder)})
+		}
+		ordered = append(ordered, yaml.MapItem{Key: k, Value: orderedPipelines})
+	}
+	return ordered
+}
+
+func orderedPipelineIds(pipelines map[string]any) []string {
+	return slices.SortedStableFunc(maps.Keys(pipelines), func(a, b string) int {
+		aSignal, _, _ := strings.Cut(a, "/")
+		bSignal, _, _ := strings.Cut(b, "/")
+		if c := signalIndex(aSignal) - signalIndex(bSignal); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+}
+
+func signalIndex(signal string) int {
+	if i := slices.Index(pipelineSignals, signal); i >= 0 {
+		return i
+	}
+	return len(pipelineSignals)
+}
+
+func orderMap(value map[string]any, preferred []string) yaml.MapSlice {
+	ordered := yaml.MapSlice{}
+	for _, k := range orderedKeys(value, preferred) {
+		ordered = append(ordered, yaml.MapItem{Key: k, Value: orderValue(value[k])})
+	}
+	return ordered
+}
+
+func orderValue(value any) any {
+	switch {
+	case isMap(value):
+		return orderMap(value.(map[string]any), nil)
+	case isSlice(value):
+		list := value.([]any)
+		ordered := make([]any, len(list))
+		for i, item := range list {
+			ordered[i] = orderValue(item)
+		}
+		return ordered
+	}
+	return value
+}
+
+func orderedKeys(value map[string]any, preferred []string) []string {
+	var keys []string
+	for _, k := range preferred {
+		if _, ok := value[k]; ok && !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(value)) {
+		if !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
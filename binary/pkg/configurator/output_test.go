@@ -0,0 +1,118 @@
+package configurator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalConfiguration(t *testing.T) {
+	configuration := map[string]any{
+		"service": map[string]any{
+			"telemetry":  map[string]any{"logs": map[string]any{"level": "info"}},
+			"extensions": []any{"health_check"},
+			"pipelines": map[string]any{
+				"logs":            map[string]any{"exporters": []any{"debug"}, "receivers": []any{"otlp"}},
+				"metrics/host":    map[string]any{"exporters": []any{"debug"}, "processors": []any{"batch"}, "receivers": []any{"otlp"}},
+				"traces":          map[string]any{"exporters": []any{"debug"}, "receivers": []any{"otlp"}},
+				"metrics":         map[string]any{"exporters": []any{"debug"}, "receivers": []any{"otlp"}},
+				"custom/pipeline": map[string]any{},
+			},
+		},
+		"exporters": map[string]any{
+			"debug": map[string]any{"verbosity": "detailed", "sampling_initial": 5},
+		},
+		"processors": map[string]any{
+			"memory_limiter": map[string]any{},
+			"batch":          map[string]any{"timeout": "1s"},
+		},
+		"receivers": map[string]any{
+			"otlp": map[string]any{
+				"protocols": map[string]any{
+					"http": map[string]any{"endpoint": "localhost:4318"},
+					"grpc": map[string]any{"endpoint": "localhost:4317"},
+				},
+			},
+		},
+		"extensions": map[string]any{"health_check": map[string]any{}},
+	}
+	order := outputOrder{
+		"processors": []string{"memory_limiter", "batch"},
+	}
+
+	data, err := marshalConfiguration(configuration, order, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `extensions:
+  health_check: {}
+receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: localhost:4317
+      http:
+        endpoint: localhost:4318
+processors:
+  memory_limiter: {}
+  batch:
+    timeout: 1s
+exporters:
+  debug:
+    sampling_initial: 5
+    verbosity: detailed
+service:
+  extensions:
+  - health_check
+  pipelines:
+    traces:
+      receivers:
+      - otlp
+      exporters:
+      - debug
+    metrics:
+      receivers:
+      - otlp
+      exporters:
+      - debug
+    metrics/host:
+      receivers:
+      - otlp
+      processors:
+      - batch
+      exporters:
+      - debug
+    logs:
+      receivers:
+      - otlp
+      exporters:
+      - debug
+    custom/pipeline: {}
+  telemetry:
+    logs:
+      level: info
+`, string(data))
+
+	again, err := marshalConfiguration(configuration, order, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, string(data), string(again))
+}
+
+func TestParseRecipeKeepsComponentsOrder(t *testing.T) {
+	recipe, err := ParseRecipe(strings.NewReader(`
+description: Order test
+args: {}
+components:
+  zeta:
+    source: receivers/otlp.yml
+  alpha:
+    source: processors/batch.yml
+  middle:
+    source: exporters/debug.yml
+service: {}
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"zeta", "alpha", "middle"}, recipe.declaredComponentKeys())
+
+	recipe.componentsOrder = nil
+	assert.Equal(t, []string{"alpha", "middle", "zeta"}, recipe.declaredComponentKeys())
+}
@@ -0,0 +1,500 @@
+// Package configurator builds EDOT Collector configurations out of recipes and the components they reference.
+package configurator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+const recipeServiceSource = "the recipe service"
+
+var (
+	yamlFileNamePattern = regexp.MustCompile(`(.+)\.[yY][aA]?[mM][lL]`)
+	anyArgPattern       = regexp.MustCompile(fmt.Sprintf("%s|%s|%s", `\$const\.[^\s]+`, `\$args\.[^\s]+`, `\$components\.[^\s]+`))
+)
+
+// BuildOptions configures how a recipe is built. Components are read from the Components file system, using the recipe
+// components' sources as paths. Schemas, when set, provides '<kind>/<type>.schema.json' files for the components that
+// don't declare a schema. Components are loaded through ComponentCache, which must read from the Components file
+// system, so that builds sharing a NewComponentCache parse each component file once. When it's not set, each build uses
+// its own cache, so that recipe components sharing a source still parse it once. IgnoreEnv leaves out the args' env
+// vars, so that only the provided args and the default values are used.
+type BuildOptions struct {
+	Args             map[string]string
+	Components       fs.FS
+	CollectorVersion string
+	Schemas          fs.FS
+	ComponentCache   ComponentLoader
+	RecipePath       string
+	Annotate         bool
+	PruneUnused      bool
+	IgnoreEnv        bool
+	Trace            func(Resolution)
+	Warn             func(string)
+}
+
+type Arg struct {
+	Description string `validate:"required"`
+	Env         string
+	Default     string
+	Secret      bool
+}
+
+type RecipeComponent struct {
+	Source         string `validate:"required"`
+	Name           string
+	Configurations []string
+	Vars           Vars
+}
+
+type Recipe struct {
+	Args             map[string]Arg             `validate:"required"`
+	Description      string                     `validate:"required"`
+	CollectorVersion string                     `yaml:"collector_version" validate:"omitempty,version"`
+	Components       map[string]RecipeComponent `validate:"required"`
+	Service          map[string]any             `validate:"required"`
+	Const            map[string]any
+	Lint             LintOptions
+	componentsOrder  []string
+}
+
+func ParseRecipe(source io.Reader) (Recipe, error) {
+	recipe := &Recipe{}
+	data, err := io.ReadAll(source)
+	if err != nil {
+		return *recipe, err
+	}
+	err = parseYamlFile(bytes.NewReader(data), recipe)
+	if err != nil {
+		return *recipe, err
+	}
+	var declared struct {
+		Components yaml.MapSlice
+	}
+	err = yaml.Unmarshal(data, &declared)
+	for _, item := range declared.Components {
+		recipe.componentsOrder = append(recipe.componentsOrder, fmt.Sprint(item.Key))
+	}
+	return *recipe, err
+}
+
+func (r *Recipe) declaredComponentKeys() []string {
+	keys := slices.Clone(r.componentsOrder)
+	for _, k := range slices.Sorted(maps.Keys(r.Components)) {
+		if !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+type loadedComponent struct {
+	Key        string
+	Definition RecipeComponent
+	Component  *Component
+	Kind       string
+	Name       string
+	Schema     *jsonschema.Schema
+}
+
+func BuildRecipe(recipe *Recipe, params BuildOptions) (map[string]any, error) {
+	configuration, _, err := buildRecipeWithComponents(recipe, params)
+	return configuration, err
+}
+
+func BuildRecipeYaml(recipe *Recipe, params BuildOptions) ([]byte, error) {
+	configuration, components, err := buildRecipeWithComponents(recipe, params)
+	if err != nil {
+		return nil, err
+	}
+	if !params.Annotate {
+		return marshalConfiguration(configuration, recipe.outputOrder(components), nil)
+	}
+	header, err := annotationHeader(recipe, params, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	data, err := marshalConfiguration(configuration, recipe.outputOrder(components), componentComments(recipe, components))
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(header), data...), nil
+}
+
+func buildRecipeWithComponents(recipe *Recipe, params BuildOptions) (map[string]any, map[string]*loadedComponent, error) {
+	var err error
+	unused := findUnused(recipe)
+	for _, message := range unused.messages() {
+		params.warn(message)
+	}
+	if params.PruneUnused {
+		recipe = pruneComponents(recipe, unused.Components)
+	}
+	if params.ComponentCache == nil {
+		params.ComponentCache = NewComponentCache(params.Components)
+	}
+	components, err := loadRecipeComponents(recipe, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = errors.Join(
+		validateServiceReferences(recipe.Service, components),
+		validatePipelines(recipe.Service, components),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	componentNames := make(map[string]string, len(components))
+	for k, v := range components {
+		componentNames[k] = v.Name
+	}
+	allArguments, err := collectAllArguments(recipe, params, componentNames)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, argSources, err := collectArgs(recipe.Args, params.Args, params.IgnoreEnv)
+	if err != nil {
+		return nil, nil, err
+	}
+	builtComponents := make(map[string]any)
+	origins := make(map[string]string)
+	for _, k := range slices.Sorted(maps.Keys(components)) {
+		v := components[k]
+		component, err := buildComponent(v.Component, v.Name, v.Definition, allArguments, ComponentParams{
+			VarsScope: fmt.Sprintf("recipe component '%s' vars", k),
+			Trace:     params.componentTrace(v),
+		}, argSources)
+		if err != nil {
+			return nil, nil, &ComponentError{Key: k, Source: v.Definition.Source, Err: err}
+		}
+		err = mergeMaps(builtComponents, map[string]any{
+			v.Kind: component,
+		}, mergeOptions{
+			Strategy:  MergeStrategyError,
+			SrcSource: fmt.Sprintf("recipe component '%s'", k),
+			Origins:   origins,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	resolvedServices := deepCopy(recipe.Service)
+	err = replacePlaceholdersInMapAt(resolvedServices, []string{"service"}, *anyArgPattern, allArguments, params.recipeTracer(argSources))
+	if err != nil {
+		return nil, nil, err
+	}
+	traceOrigins(params.Trace, resolvedServices, []string{"service"}, "", recipeServiceSource)
+	err = mergeMaps(builtComponents, map[string]any{
+		"service": resolvedServices,
+	}, mergeOptions{
+		Strategy:  MergeStrategyError,
+		SrcSource: recipeServiceSource,
+		Origins:   origins,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	err = errors.Join(
+		validateAuthenticators(builtComponents, components),
+		validateListenEndpoints(builtComponents),
+		validateComponentSchemas(builtComponents, components),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return builtComponents, components, nil
+}
+
+func pruneComponents(recipe *Recipe, keys []string) *Recipe {
+	pruned := *recipe
+	pruned.Components = maps.Clone(recipe.Components)
+	for _, k := range keys {
+		delete(pruned.Components, k)
+	}
+	return &pruned
+}
+
+func loadRecipeComponents(recipe *Recipe, params BuildOptions) (map[string]*loadedComponent, error) {
+	components := make(map[string]*loadedComponent, len(recipe.Components))
+	namedBy := make(map[string]string)
+	for _, k := range slices.Sorted(maps.Keys(recipe.Components)) {
+		v := recipe.Components[k]
+		component, err := params.ComponentCache.Load(v.Source)
+		if err != nil {
+			return nil, &ComponentError{Key: k, Source: v.Source, Err: err}
+		}
+		err = params.checkCompatibility(k, v, component, recipe)
+		if err != nil {
+			return nil, &ComponentError{Key: k, Source: v.Source, Err: err}
+		}
+		kind, typeName := component.Metadata.KindAndType(v.Source)
+		if typeName == "" {
+			return nil, fmt.Errorf("could not get component type from source path: '%s'", v.Source)
+		}
+		name := typeName
+		if len(v.Name) > 0 {
+			name = fmt.Sprintf("%s/%s", name, v.Name)
+		}
+		qualifiedName := kind + "::" + name
+		if other, ok := namedBy[qualifiedName]; ok {
+			return nil, fmt.Errorf("components '%s' and '%s' are both named '%s' within '%s', set a different 'name' to one of them", other, k, name, kind)
+		}
+		namedBy[qualifiedName] = k
+		schema, err := loadComponentSchema(component, params.Components, v.Source, kind, typeName, params.Schemas)
+		if err != nil {
+			return nil, &ComponentError{Key: k, Source: v.Source, Err: err}
+		}
+		components[k] = &loadedComponent{
+			Key:        k,
+			Definition: v,
+			Component:  component,
+			Kind:       kind,
+			Name:       name,
+			Schema:     schema,
+		}
+	}
+	return components, nil
+}
+
+func (p BuildOptions) componentTrace(component *loadedComponent) func(Resolution) {
+	if p.Trace == nil {
+		return nil
+	}
+	return func(r Resolution) {
+		r.Path = append([]string{component.Kind, component.Name}, r.Path...)
+		r.Source = component.Definition.Source
+		p.Trace(r)
+	}
+}
+
+func (p BuildOptions) recipeTracer(argSources map[string]string) placeholderTracer {
+	if p.Trace == nil {
+		return nil
+	}
+	return func(path []string, text string, placeholder string, value any) {
+		p.Trace(Resolution{
+			Path:        path,
+			Text:        text,
+			Placeholder: placeholder,
+			Value:       value,
+			Scope:       recipeScope(placeholder, argSources),
+			Source:      recipeServiceSource,
+		})
+	}
+}
+
+func recipeScope(placeholder string, argSources map[string]string) string {
+	switch {
+	case strings.HasPrefix(placeholder, "$args."):
+		if source, ok := argSources[strings.TrimPrefix(placeholder, "$args.")]; ok {
+			return fmt.Sprintf("recipe args, from %s", source)
+		}
+		return "recipe args"
+	case strings.HasPrefix(placeholder, "$const."):
+		return "recipe const"
+	case strings.HasPrefix(placeholder, "$components."):
+		return "recipe components"
+	}
+	return "recipe"
+}
+
+func (p BuildOptions) checkCompatibility(key string, componentDef RecipeComponent, component *Component, recipe *Recipe) error {
+	collectorVersion := p.CollectorVersion
+	if collectorVersion == "" {
+		collectorVersion = recipe.CollectorVersion
+	}
+	warnings, err := component.Metadata.checkCollectorVersion(collectorVersion)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		p.warn(fmt.Sprintf("component '%s' (%s) %s", key, componentDef.Source, warning))
+	}
+	return nil
+}
+
+func (p BuildOptions) warn(message string) {
+	if p.Warn != nil {
+		p.Warn(message)
+	}
+}
+
+// LoadComponent parses the component file at the given path of fsys.
+func LoadComponent(fsys fs.FS, path string) (*Component, error) {
+	if fsys == nil {
+		return nil, fmt.Errorf("no components file system provided")
+	}
+	componentFile, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer componentFile.Close()
+
+	return ParseComponent(componentFile)
+}
+
+// ComponentFile is a component along with its path within the components file system.
+type ComponentFile struct {
+	Source    string
+	Component *Component
+}
+
+// ListComponents loads every component file of fsys.
+func ListComponents(fsys fs.FS) ([]ComponentFile, error) {
+	var files []ComponentFile
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !yamlFileNamePattern.MatchString(d.Name()) {
+			return err
+		}
+		component, err := LoadComponent(fsys, path)
+		if err != nil {
+			return fmt.Errorf("could not load component '%s': %w", path, err)
+		}
+		files = append(files, ComponentFile{Source: path, Component: component})
+		return nil
+	})
+	return files, err
+}
+
+// LoadRecipe parses the recipe file at the given path of fsys.
+func LoadRecipe(fsys fs.FS, path string) (Recipe, error) {
+	recipeFile, err := fsys.Open(path)
+	if err != nil {
+		return Recipe{}, err
+	}
+	defer recipeFile.Close()
+
+	return ParseRecipe(recipeFile)
+}
+
+func buildComponent(component *Component, componentName string, componentDef RecipeComponent, arguments map[string]any, params ComponentParams, argSources map[string]string) (map[string]any, error) {
+	var tracer placeholderTracer
+	varsResolutions := make(map[string][]Resolution)
+	if params.Trace != nil {
+		tracer = func(path []string, text string, placeholder string, value any) {
+			varsResolutions[path[0]] = append(varsResolutions[path[0]], Resolution{
+				Path:        append([]string{"vars"}, path...),
+				Text:        text,
+				Placeholder: placeholder,
+				Value:       value,
+				Scope:       recipeScope(placeholder, argSources),
+			})
+		}
+		trace, varsScope := params.Trace, params.varsScope()
+		params.Trace = func(r Resolution) {
+			if name, ok := strings.CutPrefix(r.Placeholder, "$vars."); ok && r.Scope == varsScope {
+				r.Via = varsResolutions[name]
+			}
+			trace(r)
+		}
+	}
+	vars, err := resolveVars(componentDef.Vars, arguments, tracer)
+	if err != nil {
+		return nil, err
+	}
+	params.Name = componentName
+	params.ConfigurationNames = componentDef.Configurations
+	params.Vars = vars
+	return buildParsedComponent(component, params)
+}
+
+func collectAllArguments(recipe *Recipe, params BuildOptions, componentNames map[string]string) (map[string]any, error) {
+	argsRefs, err := getArgsRefs(recipe.Args, params.Args, params.IgnoreEnv)
+	if err != nil {
+		return nil, err
+	}
+	constRefs, err := getConstantsRefs(recipe.Const)
+	if err != nil {
+		return nil, err
+	}
+	componentNameRefs, err := prependToKeysOfPrimitiveValues(componentNames, "$components.")
+	if err != nil {
+		return nil, err
+	}
+	allValues := make(map[string]any)
+	for k, v := range argsRefs {
+		allValues[k] = v
+	}
+	for k, v := range constRefs {
+		allValues[k] = v
+	}
+	for k, v := range componentNameRefs {
+		allValues[k] = v
+	}
+	return allValues, nil
+}
+
+func resolveVars(vars Vars, arguments map[string]any, tracer placeholderTracer) (map[string]any, error) {
+	result := make(map[string]any)
+	for k, v := range vars {
+		if isString(v) {
+			resolved, err := resolvePlaceholdersInStringAt(v.(string), []string{k}, *anyArgPattern, arguments, tracer)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = resolved
+		} else {
+			result[k] = v
+		}
+	}
+	return result, nil
+}
+
+func getConstantsRefs(provided map[string]any) (map[string]any, error) {
+	return prependToKeysOfPrimitiveValues(provided, "$const.")
+}
+
+func getArgsRefs(argsDef map[string]Arg, providedArgs map[string]string, ignoreEnv bool) (map[string]string, error) {
+	collected, _, err := collectArgs(argsDef, providedArgs, ignoreEnv)
+	if err != nil {
+		return nil, err
+	}
+	return prependToKeysOfPrimitiveValues(collected, "$args.")
+}
+
+func collectArgs(argsDef map[string]Arg, providedArgs map[string]string, ignoreEnv bool) (map[string]string, map[string]string, error) {
+	collected := make(map[string]string, len(argsDef))
+	sources := make(map[string]string, len(argsDef))
+	for k, v := range providedArgs {
+		collected[k] = v
+		sources[k] = fmt.Sprintf("the -A%s flag", k)
+	}
+	for k, v := range argsDef {
+		if _, ok := collected[k]; ok {
+			continue
+		}
+		envVarValue, err := getEnvVar(v.Env)
+		switch {
+		case err == nil && !ignoreEnv:
+			collected[k] = envVarValue
+			sources[k] = fmt.Sprintf("the %s env var", v.Env)
+		case v.Default != "":
+			collected[k] = v.Default
+			sources[k] = "its default value"
+		default:
+			return nil, nil, &ArgError{Name: k, Env: v.Env}
+		}
+	}
+	return collected, sources, nil
+}
+
+func getEnvVar(name string) (string, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return "", fmt.Errorf("env var not found: '%s'", name)
+	}
+	return value, nil
+}
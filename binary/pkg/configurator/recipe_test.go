@@ -0,0 +1,414 @@
+package configurator
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var dummyComponent = `
+vars:
+  endpoint: http://localhost:8080
+  api_key: default_api_key
+  some_var:
+    type: string
+  some_component_name:
+    description: The name of another component
+    type: string
+refs:
+  base:
+    es_endpoint: $vars.endpoint
+    es_api_key: $vars.api_key
+configurations:
+  default:
+    content: $refs.base
+  someconfig:
+    content: $refs.base
+    append:
+      - path: "$"
+        content:
+          extra_key: $vars.some_var and $vars.some_component_name
+`
+var dummyRecipe = `
+description: Dummy recipe for tests
+args:
+  endpoint:
+    description: ES endpoint
+    env: ELASTICSEARCH_ENDPOINT
+  api_key:
+    description: ES api key
+    env: ELASTICSEARCH_API_KEY
+const:
+  a_global_var: http://recipe.global.endpoint
+components:
+  my-exporter:
+    source: dummypath/dummy.yml
+    name: custom-name
+    vars:
+      endpoint: $const.a_global_var
+      api_key: $args.api_key
+  my-other-exporter:
+    source: dummypath/dummy.yml
+    configurations: [someconfig]
+    vars:
+      endpoint: $args.endpoint
+      api_key: my-other-exporter-key
+      some_var: other-extra-value
+      some_component_name: $components.my-exporter
+  my-receiver:
+    source: dummypath/dummyreceiver.yml
+service:
+  pipelines:
+    traces:
+      receivers: [ $components.my-receiver ]
+      exporters: [ $components.my-exporter ]
+    traces/something:
+      receivers: [ $components.my-receiver ]
+      exporters: [ $components.my-other-exporter ]
+`
+
+var dummyReceiverComponent = `
+configurations:
+  default:
+    content: {}
+`
+
+const (
+	providedEndpoint = "http://external.endpoint"
+	providedApiKey   = "external_api_key"
+)
+
+func TestBuildRecipe(t *testing.T) {
+	componentsTempDir, err := os.MkdirTemp("", "components")
+	assert.NoError(t, err)
+	os.Setenv("ELASTICSEARCH_ENDPOINT", "http://endpoint.from.env")
+	os.Setenv("ELASTICSEARCH_API_KEY", providedApiKey)
+	defer os.Unsetenv("ELASTICSEARCH_ENDPOINT")
+	defer os.Unsetenv("ELASTICSEARCH_API_KEY")
+	defer os.RemoveAll(componentsTempDir)
+
+	testDirPath := filepath.Join(componentsTempDir, "dummypath")
+	err = os.Mkdir(testDirPath, 0755)
+	assert.NoError(t, err)
+	dummyComponentFilePath := filepath.Join(testDirPath, "dummy.yml")
+	err = os.WriteFile(dummyComponentFilePath, []byte(dummyComponent), 0755)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(testDirPath, "dummyreceiver.yml"), []byte(dummyReceiverComponent), 0755)
+	assert.NoError(t, err)
+
+	recipe, err := ParseRecipe(strings.NewReader(dummyRecipe))
+	assert.NoError(t, err)
+	data, err := BuildRecipe(&recipe, BuildOptions{
+		Components: os.DirFS(componentsTempDir),
+		Args: map[string]string{
+			"endpoint": providedEndpoint,
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"dummypath": map[string]any{
+			"dummy": map[string]any{
+				"es_api_key":  "my-other-exporter-key",
+				"es_endpoint": "http://external.endpoint",
+				"extra_key":   "other-extra-value and dummy/custom-name",
+			},
+			"dummy/custom-name": map[string]any{
+				"es_api_key":  "external_api_key",
+				"es_endpoint": "http://recipe.global.endpoint",
+			},
+			"dummyreceiver": map[string]any{},
+		},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"traces": map[string]any{
+					"receivers": []any{"dummyreceiver"},
+					"exporters": []any{"dummy/custom-name"},
+				},
+				"traces/something": map[string]any{
+					"receivers": []any{"dummyreceiver"},
+					"exporters": []any{"dummy"},
+				},
+			},
+		},
+	}, data)
+}
+
+var layoutRecipe = `
+description: Recipe using components with explicit type and kind
+args: {}
+components:
+  logs-exporter:
+    source: exporters/elastic/elasticsearch-logs.yml
+    name: logs
+  traces-exporter:
+    source: exporters/elastic/elasticsearch-traces.yml
+    name: traces
+  otlp:
+    source: shared/otlp.yml
+service:
+  pipelines:
+    logs:
+      receivers: [ $components.otlp ]
+      exporters: [ $components.logs-exporter ]
+    traces:
+      receivers: [ $components.otlp ]
+      exporters: [ $components.traces-exporter ]
+`
+
+// writeFiles writes the files, keyed by their paths, to a temporary directory and returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		assert.NoError(t, os.WriteFile(fullPath, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestBuildRecipeWithExplicitTypeAndKind(t *testing.T) {
+	componentsDir := writeFiles(t, map[string]string{
+		"exporters/elastic/elasticsearch-logs.yml": `
+metadata:
+  type: elasticsearch
+configurations:
+  default:
+    content:
+      logs_index: logs
+`,
+		"exporters/elastic/elasticsearch-traces.yml": `
+metadata:
+  type: elasticsearch
+  kind: exporter
+configurations:
+  default:
+    content:
+      traces_index: traces
+`,
+		"shared/otlp.yml": `
+metadata:
+  kind: receiver
+configurations:
+  default:
+    content: {}
+`,
+	})
+
+	recipe, err := ParseRecipe(strings.NewReader(layoutRecipe))
+	assert.NoError(t, err)
+	data, err := BuildRecipe(&recipe, BuildOptions{
+		Components: os.DirFS(componentsDir),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"exporters": map[string]any{
+			"elasticsearch/logs": map[string]any{
+				"logs_index": "logs",
+			},
+			"elasticsearch/traces": map[string]any{
+				"traces_index": "traces",
+			},
+		},
+		"receivers": map[string]any{
+			"otlp": map[string]any{},
+		},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"logs": map[string]any{
+					"receivers": []any{"otlp"},
+					"exporters": []any{"elasticsearch/logs"},
+				},
+				"traces": map[string]any{
+					"receivers": []any{"otlp"},
+					"exporters": []any{"elasticsearch/traces"},
+				},
+			},
+		},
+	}, data)
+
+	recipe, err = ParseRecipe(strings.NewReader(strings.ReplaceAll(layoutRecipe, "name: traces", "name: logs")))
+	assert.NoError(t, err)
+	_, err = BuildRecipe(&recipe, BuildOptions{
+		Components: os.DirFS(componentsDir),
+	})
+	assert.EqualError(t, err, "components 'logs-exporter' and 'traces-exporter' are both named 'elasticsearch/logs' within 'exporters', set a different 'name' to one of them")
+}
+
+func TestBuildRecipeFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"recipes/otlp.yml": {Data: []byte(`
+description: Recipe loaded from a file system
+args:
+  endpoint:
+    description: The endpoint
+    env: TEST_FS_ENDPOINT
+components:
+  otlp-receiver:
+    source: receivers/otlp.yml
+  otlp-exporter:
+    source: exporters/otlp.yml
+    vars:
+      endpoint: $args.endpoint
+service:
+  pipelines:
+    traces:
+      receivers: [ $components.otlp-receiver ]
+      exporters: [ $components.otlp-exporter ]
+`)},
+		"components/receivers/otlp.yml": {Data: []byte(`
+schema: otlp.schema.json
+configurations:
+  default:
+    content:
+      protocols: {}
+`)},
+		"components/receivers/otlp.schema.json": {Data: []byte(`{"type": "object", "required": ["protocols"]}`)},
+		"components/exporters/otlp.yml": {Data: []byte(`
+vars:
+  endpoint:
+    required: true
+configurations:
+  default:
+    content:
+      endpoint: $vars.endpoint
+`)},
+	}
+	components, err := fs.Sub(fsys, "components")
+	assert.NoError(t, err)
+
+	recipe, err := LoadRecipe(fsys, "recipes/otlp.yml")
+	assert.NoError(t, err)
+	data, err := BuildRecipe(&recipe, BuildOptions{
+		Args:       map[string]string{"endpoint": "localhost:4317"},
+		Components: components,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"endpoint": "localhost:4317"}, data["exporters"].(map[string]any)["otlp"])
+
+	_, err = BuildRecipe(&recipe, BuildOptions{Components: components})
+	var argErr *ArgError
+	assert.ErrorAs(t, err, &argErr)
+	assert.Equal(t, &ArgError{Name: "endpoint", Env: "TEST_FS_ENDPOINT"}, argErr)
+
+	recipe.Components["otlp-exporter"] = RecipeComponent{Source: "exporters/otlp.yml"}
+	_, err = BuildRecipe(&recipe, BuildOptions{
+		Args:       map[string]string{"endpoint": "localhost:4317"},
+		Components: components,
+	})
+	var componentErr *ComponentError
+	assert.ErrorAs(t, err, &componentErr)
+	assert.Equal(t, "otlp-exporter", componentErr.Key)
+	assert.Equal(t, "exporters/otlp.yml", componentErr.Source)
+	assert.EqualError(t, err, "component 'otlp-exporter' (exporters/otlp.yml): var 'endpoint' is required but it wasn't provided")
+
+	recipe.Components["otlp-exporter"] = RecipeComponent{Source: "exporters/missing.yml"}
+	_, err = BuildRecipe(&recipe, BuildOptions{
+		Args:       map[string]string{"endpoint": "localhost:4317"},
+		Components: components,
+	})
+	assert.ErrorAs(t, err, &componentErr)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	files, err := ListComponents(components)
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, "exporters/otlp.yml", files[0].Source)
+}
+
+func TestCollectArgs(t *testing.T) {
+	t.Setenv("TEST_ARGS_ENV", "from env")
+	values, sources, err := collectArgs(map[string]Arg{
+		"flag":    {Description: "Flag arg", Env: "TEST_ARGS_ENV"},
+		"env":     {Description: "Env arg", Env: "TEST_ARGS_ENV", Default: "unused"},
+		"default": {Description: "Default arg", Env: "TEST_ARGS_MISSING", Default: "from default"},
+	}, map[string]string{"flag": "from flag"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"flag":    "from flag",
+		"env":     "from env",
+		"default": "from default",
+	}, values)
+	assert.Equal(t, map[string]string{
+		"flag":    "the -Aflag flag",
+		"env":     "the TEST_ARGS_ENV env var",
+		"default": "its default value",
+	}, sources)
+
+	_, _, err = collectArgs(map[string]Arg{
+		"missing": {Description: "Missing arg", Env: "TEST_ARGS_MISSING"},
+	}, nil, false)
+	assert.EqualError(t, err, "arg 'missing' not provided - you may provide via the env var: 'TEST_ARGS_MISSING' or via the command line argument: '-Amissing'")
+
+	values, _, err = collectArgs(map[string]Arg{
+		"env": {Description: "Env arg", Env: "TEST_ARGS_ENV", Default: "from default"},
+	}, nil, true)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "from default"}, values)
+
+	_, _, err = collectArgs(map[string]Arg{
+		"env": {Description: "Env arg", Env: "TEST_ARGS_ENV"},
+	}, nil, true)
+	var argErr *ArgError
+	assert.ErrorAs(t, err, &argErr)
+}
+
+func TestBuildRecipeWithArgDefaults(t *testing.T) {
+	componentsFS := fstest.MapFS{
+		"dummypath/dummy.yml":         {Data: []byte(dummyComponent)},
+		"dummypath/dummyreceiver.yml": {Data: []byte(dummyReceiverComponent)},
+	}
+	recipe, err := ParseRecipe(strings.NewReader(strings.Replace(dummyRecipe, "    env: ELASTICSEARCH_ENDPOINT\n", "    env: TEST_ARGS_MISSING\n    default: http://default.endpoint\n", 1)))
+	assert.NoError(t, err)
+
+	data, err := BuildRecipe(&recipe, BuildOptions{Components: componentsFS, Args: map[string]string{"api_key": "key"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "http://default.endpoint", data["dummypath"].(map[string]any)["dummy"].(map[string]any)["es_endpoint"])
+
+	data, err = BuildRecipe(&recipe, BuildOptions{Components: componentsFS, Args: map[string]string{"api_key": "key", "endpoint": providedEndpoint}})
+	assert.NoError(t, err)
+	assert.Equal(t, providedEndpoint, data["dummypath"].(map[string]any)["dummy"].(map[string]any)["es_endpoint"])
+
+	_, err = BuildRecipe(&recipe, BuildOptions{Components: componentsFS, IgnoreEnv: true})
+	assert.EqualError(t, err, "arg 'api_key' not provided - you may provide via the env var: 'ELASTICSEARCH_API_KEY' or via the command line argument: '-Aapi_key'")
+}
+
+func TestBuildRecipeReportsComponentErrorsInOrder(t *testing.T) {
+	componentsFS := fstest.MapFS{
+		"receivers/otlp.yml":  {Data: []byte("configurations:\n  default:\n    content:\n      endpoint: $vars.missing\n")},
+		"exporters/debug.yml": {Data: []byte("configurations:\n  default:\n    content: {}\n")},
+	}
+	recipe, err := ParseRecipe(strings.NewReader(`
+description: Recipe with several broken components
+args: {}
+components:
+  c:
+    source: receivers/otlp.yml
+    name: c
+  a:
+    source: receivers/otlp.yml
+    name: a
+  b:
+    source: receivers/otlp.yml
+    name: b
+  debug:
+    source: exporters/debug.yml
+service:
+  pipelines:
+    traces:
+      receivers: [ $components.c, $components.a, $components.b ]
+      exporters: [ $components.debug ]
+`))
+	assert.NoError(t, err)
+	for range 20 {
+		_, err = BuildRecipe(&recipe, BuildOptions{Components: componentsFS})
+		var componentErr *ComponentError
+		if assert.ErrorAs(t, err, &componentErr) {
+			assert.Equal(t, "a", componentErr.Key)
+		}
+	}
+}
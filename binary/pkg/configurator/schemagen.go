@@ -0,0 +1,133 @@
+package configurator
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var fileSchemaTypes = map[string]reflect.Type{
+	"recipe":    reflect.TypeFor[Recipe](),
+	"component": reflect.TypeFor[Component](),
+}
+
+type jsonSchemaProvider interface {
+	jsonSchema() map[string]any
+}
+
+func (StringList) jsonSchema() map[string]any {
+	return map[string]any{
+		"oneOf": []any{
+			map[string]any{"type": "string"},
+			map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+	}
+}
+
+func (VarDecl) jsonSchema() map[string]any {
+	return map[string]any{
+		"anyOf": []any{
+			map[string]any{"type": []any{"string", "number", "boolean", "array", "null"}},
+			structSchema(reflect.TypeFor[VarDecl]()),
+		},
+	}
+}
+
+func FileSchema(name string) (map[string]any, error) {
+	t, ok := fileSchemaTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown schema '%s', must be one of: %s", name, strings.Join(slices.Sorted(maps.Keys(fileSchemaTypes)), ", "))
+	}
+	schema := typeSchema(t)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = fmt.Sprintf("EDOT Collector Configurator %s", name)
+	return schema, nil
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	if t.Implements(reflect.TypeFor[jsonSchemaProvider]()) {
+		return reflect.Zero(t).Interface().(jsonSchemaProvider).jsonSchema()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	}
+	return map[string]any{}
+}
+
+func structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []any
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := yamlFieldName(field)
+		property := typeSchema(field.Type)
+		if applyValidateTag(property, field.Tag.Get("validate")) {
+			required = append(required, name)
+		}
+		properties[name] = property
+	}
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func yamlFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+func applyValidateTag(schema map[string]any, tag string) bool {
+	required := false
+	target := schema
+	for _, rule := range strings.Split(tag, ",") {
+		name, value, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "dive":
+			target, _ = target["items"].(map[string]any)
+			if target == nil {
+				return required
+			}
+		case "oneof":
+			var enum []any
+			for _, option := range strings.Fields(value) {
+				enum = append(enum, option)
+			}
+			target["enum"] = enum
+		case "version":
+			target["pattern"] = versionPattern.String()
+		}
+	}
+	return required
+}
@@ -0,0 +1,95 @@
+package configurator
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSchemasInSync(t *testing.T) {
+	for name := range fileSchemaTypes {
+		t.Run(name, func(t *testing.T) {
+			schema, err := FileSchema(name)
+			assert.NoError(t, err)
+			expected, err := marshalJsonLine(schema)
+			assert.NoError(t, err)
+			actual, err := os.ReadFile(filepath.Join("..", "..", "..", "schemas", name+".schema.json"))
+			assert.NoError(t, err)
+			assert.Equal(t, string(expected), string(actual), "schemas/%s.schema.json is outdated, regenerate it with: ./configurator schema %s > schemas/%s.schema.json", name, name, name)
+		})
+	}
+}
+
+func TestFileSchemasMatchRepositoryFiles(t *testing.T) {
+	for name, dir := range map[string]string{
+		"recipe":    filepath.Join("..", "..", "..", "recipes"),
+		"component": filepath.Join("..", "..", "..", "components"),
+	} {
+		schema, err := FileSchema(name)
+		assert.NoError(t, err)
+		compiled, err := compileSchema(nil, name+".schema.json", schema)
+		assert.NoError(t, err)
+		err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err == nil && d.IsDir() && strings.HasSuffix(d.Name(), recipeTestsDirSuffix) {
+				return filepath.SkipDir
+			}
+			if err != nil || d.IsDir() || !yamlFileNamePattern.MatchString(d.Name()) {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			var value any
+			if err := yaml.Unmarshal(data, &value); err != nil {
+				return err
+			}
+			schemaErrs, err := validateWithSchema(compiled, value)
+			assert.NoError(t, err)
+			assert.Empty(t, schemaErrs, path)
+			return nil
+		})
+		assert.NoError(t, err)
+	}
+}
+
+func TestFileSchemaRejectsInvalidFiles(t *testing.T) {
+	schema, err := FileSchema("component")
+	assert.NoError(t, err)
+	compiled, err := compileSchema(nil, "component.schema.json", schema)
+	assert.NoError(t, err)
+	var component any
+	assert.NoError(t, yaml.Unmarshal([]byte(`
+metadata:
+  kind: receivers
+vars:
+  port: 4317
+  endpoint:
+    type: text
+configurations:
+  default:
+    extends: [ base ]
+    unknown: true
+`), &component))
+
+	schemaErrs, err := validateWithSchema(compiled, component)
+	assert.NoError(t, err)
+	var messages []string
+	for _, err := range schemaErrs {
+		messages = append(messages, err.Error())
+	}
+	assert.Equal(t, []string{
+		"configurations.default: additional properties 'unknown' not allowed",
+		"metadata.kind: value must be one of 'receiver', 'processor', 'exporter', 'extension', 'connector'",
+		"vars.endpoint.type: value must be one of 'any', 'string', 'bool', 'int', 'float', 'number'",
+		"vars.endpoint: got object, want null or boolean or number or string or array",
+	}, messages)
+
+	_, err = FileSchema("unknown")
+	assert.EqualError(t, err, "unknown schema 'unknown', must be one of: component, recipe")
+}
@@ -0,0 +1,370 @@
+package configurator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const maxBuildRequestSize = 1 << 20
+
+// ServerOptions configures the handler returned by NewHandler. Recipes and Components are the file systems recipes
+// and components are read from, and Schemas, when set, is used as BuildOptions.Schemas. Log, when set, receives a line
+// per request with its method, path, status and duration; request bodies, and therefore args, are never logged.
+type ServerOptions struct {
+	Recipes    fs.FS
+	Components fs.FS
+	Schemas    fs.FS
+	Log        func(string)
+}
+
+// BuildRequest is the body of a build request. Args are the only source of the recipe args besides their default
+// values: the env vars of the server are never used.
+type BuildRequest struct {
+	Args             map[string]string `json:"args"`
+	CollectorVersion string            `json:"collector_version"`
+	Prune            bool              `json:"prune"`
+	Annotate         bool              `json:"annotate"`
+}
+
+// BuildResponse is the body of a build request answered in the json format.
+type BuildResponse struct {
+	Configuration map[string]any `json:"configuration"`
+	Warnings      []string       `json:"warnings"`
+}
+
+type RecipeSummary struct {
+	Path        string `json:"path"`
+	Description string `json:"description"`
+}
+
+type ComponentSummary struct {
+	Source      string   `json:"source"`
+	Kind        string   `json:"kind"`
+	Type        string   `json:"type"`
+	Description string   `json:"description"`
+	Stability   string   `json:"stability,omitempty"`
+	Signals     []string `json:"signals,omitempty"`
+}
+
+type RecipeArgInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Env         string `json:"env,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Secret      bool   `json:"secret"`
+	Required    bool   `json:"required"`
+}
+
+type RecipeComponentInfo struct {
+	Key         string `json:"key"`
+	Source      string `json:"source"`
+	Kind        string `json:"kind,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+type RecipeInfo struct {
+	Path             string                `json:"path"`
+	Description      string                `json:"description"`
+	CollectorVersion string                `json:"collector_version,omitempty"`
+	Args             []RecipeArgInfo       `json:"args"`
+	Components       []RecipeComponentInfo `json:"components"`
+}
+
+type httpError struct {
+	status  int
+	message string
+}
+
+func (e *httpError) Error() string {
+	return e.message
+}
+
+type server struct {
+	options ServerOptions
+}
+
+// NewHandler returns an http.Handler serving the following endpoints:
+//
+//	GET  /recipes                lists the recipes
+//	GET  /recipes/{path}         describes a recipe, its args and components
+//	POST /recipes/{path}/build   builds a recipe from a BuildRequest, as yaml (default) or json (?format=json)
+//	GET  /components             lists the components
+//
+// Errors are answered with a {"error": "..."} json body.
+func NewHandler(options ServerOptions) http.Handler {
+	s := &server{options: options}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /recipes", s.handle(s.listRecipes))
+	mux.HandleFunc("GET /recipes/{path...}", s.handle(s.recipeInfo))
+	mux.HandleFunc("POST /recipes/{path...}", s.handle(s.buildRecipe))
+	mux.HandleFunc("GET /components", s.handle(s.listComponents))
+	return s.logRequests(mux)
+}
+
+func (s *server) handle(handler func(w http.ResponseWriter, r *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := handler(w, r)
+		if err == nil {
+			return
+		}
+		status := http.StatusInternalServerError
+		var httpErr *httpError
+		if errors.As(err, &httpErr) {
+			status = httpErr.status
+		}
+		writeJson(w, status, map[string]string{"error": err.Error()})
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (s *server) logRequests(next http.Handler) http.Handler {
+	if s.options.Log == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		s.options.Log(fmt.Sprintf("%s %s %d %s", r.Method, r.URL.Path, recorder.status, time.Since(start).Round(time.Millisecond)))
+	})
+}
+
+func (s *server) listRecipes(w http.ResponseWriter, r *http.Request) error {
+	recipes := []RecipeSummary{}
+	err := fs.WalkDir(s.options.Recipes, ".", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && strings.HasSuffix(d.Name(), recipeTestsDirSuffix) {
+			return fs.SkipDir
+		}
+		if err != nil || d.IsDir() || !yamlFileNamePattern.MatchString(d.Name()) {
+			return err
+		}
+		recipe, err := LoadRecipe(s.options.Recipes, path)
+		if err != nil {
+			return fmt.Errorf("could not load recipe '%s': %w", path, err)
+		}
+		recipes = append(recipes, RecipeSummary{Path: path, Description: strings.TrimSpace(recipe.Description)})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return writeJson(w, http.StatusOK, map[string]any{"recipes": recipes})
+}
+
+func (s *server) listComponents(w http.ResponseWriter, r *http.Request) error {
+	files, err := ListComponents(s.options.Components)
+	if err != nil {
+		return err
+	}
+	components := []ComponentSummary{}
+	for _, file := range files {
+		kind, typeName := file.Component.Metadata.KindAndType(file.Source)
+		components = append(components, ComponentSummary{
+			Source:      file.Source,
+			Kind:        kind,
+			Type:        typeName,
+			Description: file.Component.Metadata.Description,
+			Stability:   file.Component.Metadata.Stability,
+			Signals:     file.Component.Metadata.Signals,
+		})
+	}
+	return writeJson(w, http.StatusOK, map[string]any{"components": components})
+}
+
+func (s *server) recipeInfo(w http.ResponseWriter, r *http.Request) error {
+	path := r.PathValue("path")
+	recipe, err := s.loadRecipe(path)
+	if err != nil {
+		return err
+	}
+	info := RecipeInfo{
+		Path:             path,
+		Description:      strings.TrimSpace(recipe.Description),
+		CollectorVersion: recipe.CollectorVersion,
+		Args:             []RecipeArgInfo{},
+		Components:       []RecipeComponentInfo{},
+	}
+	for _, name := range slices.Sorted(maps.Keys(recipe.Args)) {
+		arg := recipe.Args[name]
+		argInfo := RecipeArgInfo{
+			Name:        name,
+			Description: arg.Description,
+			Env:         arg.Env,
+			Secret:      arg.Secret,
+			Required:    arg.Default == "",
+		}
+		if !arg.Secret {
+			argInfo.Default = arg.Default
+		}
+		info.Args = append(info.Args, argInfo)
+	}
+	for _, key := range recipe.declaredComponentKeys() {
+		source := recipe.Components[key].Source
+		componentInfo := RecipeComponentInfo{Key: key, Source: source}
+		component, err := LoadComponent(s.options.Components, source)
+		if err != nil {
+			componentInfo.Error = fmt.Sprintf("could not load component: %v", err)
+		} else {
+			componentInfo.Kind, componentInfo.Type = component.Metadata.KindAndType(source)
+			componentInfo.Description = component.Metadata.Description
+		}
+		info.Components = append(info.Components, componentInfo)
+	}
+	return writeJson(w, http.StatusOK, info)
+}
+
+func (s *server) buildRecipe(w http.ResponseWriter, r *http.Request) error {
+	path, found := strings.CutSuffix(r.PathValue("path"), "/build")
+	if !found {
+		return &httpError{http.StatusMethodNotAllowed, "recipes can only be built via POST /recipes/{path}/build"}
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "yaml"
+	}
+	if format != "yaml" && format != "json" {
+		return &httpError{http.StatusBadRequest, fmt.Sprintf("unknown output format '%s', must be one of: yaml, json", format)}
+	}
+	recipe, err := s.loadRecipe(path)
+	if err != nil {
+		return err
+	}
+	request, err := decodeBuildRequest(http.MaxBytesReader(w, r.Body, maxBuildRequestSize), &recipe)
+	if err != nil {
+		return err
+	}
+
+	warnings := []string{}
+	options := BuildOptions{
+		Args:             request.Args,
+		Components:       s.options.Components,
+		CollectorVersion: request.CollectorVersion,
+		Schemas:          s.options.Schemas,
+		RecipePath:       path,
+		Annotate:         request.Annotate,
+		PruneUnused:      request.Prune,
+		IgnoreEnv:        true,
+		Warn: func(message string) {
+			warnings = append(warnings, message)
+		},
+	}
+	if format == "json" {
+		configuration, err := BuildRecipe(&recipe, options)
+		if err != nil {
+			return buildError(err, &recipe, request.Args)
+		}
+		return writeJson(w, http.StatusOK, BuildResponse{Configuration: configuration, Warnings: warnings})
+	}
+	data, err := BuildRecipeYaml(&recipe, options)
+	if err != nil {
+		return buildError(err, &recipe, request.Args)
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(data)
+	return err
+}
+
+func decodeBuildRequest(body io.Reader, recipe *Recipe) (BuildRequest, error) {
+	var request BuildRequest
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&request)
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return request, &httpError{http.StatusRequestEntityTooLarge, fmt.Sprintf("the request body must not exceed %d bytes", maxBytesErr.Limit)}
+	case err != nil && !errors.Is(err, io.EOF):
+		return request, &httpError{http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err)}
+	}
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(request.Args)) {
+		if _, ok := recipe.Args[name]; !ok {
+			errs = append(errs, fmt.Errorf("unknown arg '%s'", name))
+		}
+	}
+	if request.CollectorVersion != "" {
+		if _, err := parseVersion(request.CollectorVersion); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return request, &httpError{http.StatusBadRequest, errors.Join(errs...).Error()}
+	}
+	return request, nil
+}
+
+func buildError(err error, recipe *Recipe, args map[string]string) error {
+	var argErr *ArgError
+	if errors.As(err, &argErr) {
+		return &httpError{http.StatusBadRequest, fmt.Sprintf("arg '%s' not provided", argErr.Name)}
+	}
+	return &httpError{http.StatusUnprocessableEntity, redactSecretArgs(err.Error(), recipe, args)}
+}
+
+// redactSecretArgs replaces the values of the recipe's secret args, either provided or default ones, within the text.
+func redactSecretArgs(text string, recipe *Recipe, args map[string]string) string {
+	var secrets []string
+	for name, arg := range recipe.Args {
+		if !arg.isSecret(name) {
+			continue
+		}
+		for _, value := range []string{args[name], arg.Default} {
+			if value != "" {
+				secrets = append(secrets, value)
+			}
+		}
+	}
+	// Longer values go first, so that secrets containing other ones are redacted as a whole.
+	slices.SortFunc(secrets, func(a string, b string) int {
+		return len(b) - len(a)
+	})
+	for _, secret := range secrets {
+		text = strings.ReplaceAll(text, secret, redactedValue)
+	}
+	return text
+}
+
+func (s *server) loadRecipe(path string) (Recipe, error) {
+	if !fs.ValidPath(path) || !yamlFileNamePattern.MatchString(path) {
+		return Recipe{}, &httpError{http.StatusNotFound, fmt.Sprintf("recipe '%s' not found", path)}
+	}
+	recipe, err := LoadRecipe(s.options.Recipes, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Recipe{}, &httpError{http.StatusNotFound, fmt.Sprintf("recipe '%s' not found", path)}
+	}
+	if err != nil {
+		return Recipe{}, fmt.Errorf("could not load recipe '%s': %w", path, err)
+	}
+	return recipe, nil
+}
+
+func writeJson(w http.ResponseWriter, status int, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
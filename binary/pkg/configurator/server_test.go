@@ -0,0 +1,234 @@
+package configurator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) (http.Handler, *[]string) {
+	var logs []string
+	handler := NewHandler(ServerOptions{
+		Recipes:    os.DirFS("../../../recipes"),
+		Components: os.DirFS("../../../components"),
+		Log: func(message string) {
+			logs = append(logs, message)
+		},
+	})
+	return handler, &logs
+}
+
+func serve(handler http.Handler, method string, target string, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return recorder
+}
+
+func TestServerListing(t *testing.T) {
+	handler, _ := newTestServer(t)
+
+	response := serve(handler, http.MethodGet, "/recipes", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	var recipes struct{ Recipes []RecipeSummary }
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &recipes))
+	assert.Contains(t, recipes.Recipes, RecipeSummary{
+		Path:        "gateway/test/otlp.yml",
+		Description: "Receives OTLP data over HTTP (on port 4318) and gRPC (on port 4317) and exports it to Elasticsearch.",
+	})
+
+	response = serve(handler, http.MethodGet, "/components", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	var components struct{ Components []ComponentSummary }
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &components))
+	assert.NotEmpty(t, components.Components)
+	for _, component := range components.Components {
+		assert.NotEmpty(t, component.Kind, component.Source)
+		assert.NotEmpty(t, component.Type, component.Source)
+	}
+}
+
+func TestServerRecipeInfo(t *testing.T) {
+	handler, _ := newTestServer(t)
+
+	response := serve(handler, http.MethodGet, "/recipes/gateway/test/otlp.yml", "")
+	assert.Equal(t, http.StatusOK, response.Code)
+	var info RecipeInfo
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &info))
+	assert.Equal(t, []RecipeArgInfo{
+		{Name: "elastic_api_key", Description: "Your Elasticsearch API Key", Env: "ELASTIC_API_KEY", Secret: true, Required: true},
+		{Name: "elastic_endpoint", Description: "Your Elasticsearch endpoint", Env: "ELASTIC_URL", Required: true},
+	}, info.Args)
+	assert.Equal(t, "otlp", info.Components[0].Key)
+	assert.Equal(t, "receivers", info.Components[0].Kind)
+	assert.Equal(t, "otlp", info.Components[0].Type)
+
+	response = serve(handler, http.MethodGet, "/recipes/gateway/missing.yml", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.JSONEq(t, `{"error": "recipe 'gateway/missing.yml' not found"}`, response.Body.String())
+}
+
+func TestServerBuild(t *testing.T) {
+	t.Setenv("ELASTIC_API_KEY", "server-env-api-key")
+	handler, logs := newTestServer(t)
+	expected, err := os.ReadFile("../../../recipes/gateway/test/otlp.tests/default.expected.yml")
+	assert.NoError(t, err)
+	body := `{"args": {"elastic_endpoint": "http://localhost:9200", "elastic_api_key": "test-api-key"}}`
+
+	response := serve(handler, http.MethodPost, "/recipes/gateway/test/otlp.yml/build", body)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/yaml", response.Header().Get("Content-Type"))
+	failure, err := compareYaml(expected, response.Body.Bytes())
+	assert.NoError(t, err)
+	assert.Empty(t, failure)
+
+	response = serve(handler, http.MethodPost, "/recipes/gateway/test/otlp.yml/build?format=json", body)
+	assert.Equal(t, http.StatusOK, response.Code)
+	var built BuildResponse
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &built))
+	var expectedConfiguration map[string]any
+	assert.NoError(t, yaml.Unmarshal(expected, &expectedConfiguration))
+	assert.Empty(t, Diff(expectedConfiguration, built.Configuration))
+	assert.Equal(t, []string{}, built.Warnings)
+
+	for _, line := range *logs {
+		assert.NotContains(t, line, "test-api-key")
+	}
+	assert.Equal(t, "POST /recipes/gateway/test/otlp.yml/build 200", (*logs)[0][:len("POST /recipes/gateway/test/otlp.yml/build 200")])
+}
+
+func TestServerBuildErrors(t *testing.T) {
+	t.Setenv("ELASTIC_API_KEY", "server-env-api-key")
+	handler, _ := newTestServer(t)
+
+	for _, tc := range []struct {
+		testName       string
+		target         string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			testName:       "missing arg, not read from the server's env",
+			target:         "/recipes/gateway/test/otlp.yml/build",
+			body:           `{"args": {"elastic_endpoint": "http://localhost:9200"}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "arg 'elastic_api_key' not provided",
+		},
+		{
+			testName:       "unknown args",
+			target:         "/recipes/gateway/test/otlp.yml/build",
+			body:           `{"args": {"endpoint": "a", "api_key": "b"}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "unknown arg 'api_key'\nunknown arg 'endpoint'",
+		},
+		{
+			testName:       "unknown field",
+			target:         "/recipes/gateway/test/otlp.yml/build",
+			body:           `{"arguments": {}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  `invalid request body: json: unknown field "arguments"`,
+		},
+		{
+			testName:       "non string arg",
+			target:         "/recipes/gateway/test/otlp.yml/build",
+			body:           `{"args": {"elastic_endpoint": 9200}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid request body: json: cannot unmarshal number into Go struct field BuildRequest.args.elastic_endpoint of type string",
+		},
+		{
+			testName:       "invalid collector version",
+			target:         "/recipes/gateway/test/otlp.yml/build",
+			body:           `{"collector_version": "latest"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid version: 'latest'",
+		},
+		{
+			testName:       "unknown format",
+			target:         "/recipes/gateway/test/otlp.yml/build?format=toml",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "unknown output format 'toml', must be one of: yaml, json",
+		},
+		{
+			testName:       "missing recipe",
+			target:         "/recipes/gateway/missing.yml/build",
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "recipe 'gateway/missing.yml' not found",
+		},
+		{
+			testName:       "not a recipe file",
+			target:         "/recipes/gateway/build",
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "recipe 'gateway' not found",
+		},
+		{
+			testName:       "without build",
+			target:         "/recipes/gateway/test/otlp.yml",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedError:  "recipes can only be built via POST /recipes/{path}/build",
+		},
+		{
+			testName:       "too large",
+			target:         "/recipes/gateway/test/otlp.yml/build",
+			body:           `{"args": {"elastic_endpoint": "` + strings.Repeat("a", maxBuildRequestSize) + `"}}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedError:  "the request body must not exceed 1048576 bytes",
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			response := serve(handler, http.MethodPost, tc.target, tc.body)
+			assert.Equal(t, tc.expectedStatus, response.Code)
+			var body map[string]string
+			assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+			assert.Equal(t, tc.expectedError, body["error"])
+		})
+	}
+}
+
+func TestServerBuildErrorsRedactSecrets(t *testing.T) {
+	handler := NewHandler(ServerOptions{
+		Recipes: fstest.MapFS{
+			"secrets.yml": {Data: []byte(`
+description: Recipe with secrets
+args:
+  api_key:
+    description: The API key
+    default: default-api-key
+  elastic_password:
+    description: The password
+  endpoint:
+    description: The endpoint
+components:
+  otlp:
+    source: receivers/otlp.yml
+    configurations: [ http ]
+  debug:
+    source: exporters/debug.yml
+    vars:
+      verbosity: $args.verbosity
+service:
+  pipelines:
+    traces:
+      receivers: [ $components.otlp ]
+      exporters: [ $components.debug ]
+`)},
+		},
+		Components: os.DirFS("../../../components"),
+	})
+
+	response := serve(handler, http.MethodPost, "/recipes/secrets.yml/build", `{"args": {"elastic_password": "provided-password", "endpoint": "http://localhost:9200"}}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Contains(t, body["error"], "'$args.verbosity' is not defined")
+	assert.Contains(t, body["error"], "http://localhost:9200")
+	assert.NotContains(t, body["error"], "default-api-key")
+	assert.NotContains(t, body["error"], "provided-password")
+	assert.Contains(t, body["error"], redactedValue)
+}
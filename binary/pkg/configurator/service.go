@@ -0,0 +1,210 @@
+package configurator
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var pipelineAllowedKinds = map[string][]string{
+	"receivers":  {"receivers", "connectors"},
+	"processors": {"processors"},
+	"exporters":  {"exporters", "connectors"},
+}
+
+var pipelineSignals = []string{"traces", "metrics", "logs", "profiles"}
+
+type connectorUsage struct {
+	asReceiver []string
+	asExporter []string
+}
+
+func validateServiceReferences(service map[string]any, components map[string]*loadedComponent) error {
+	var errs []error
+	extensions, _ := service["extensions"].([]any)
+	errs = append(errs, checkReferencedKinds(extensions, []string{"service", "extensions"}, []string{"extensions"}, components)...)
+
+	connectors := make(map[string]*connectorUsage)
+	pipelines, _ := service["pipelines"].(map[string]any)
+	for _, pipelineId := range slices.Sorted(maps.Keys(pipelines)) {
+		pipeline, _ := pipelines[pipelineId].(map[string]any)
+		for _, role := range slices.Sorted(maps.Keys(pipelineAllowedKinds)) {
+			references, _ := pipeline[role].([]any)
+			path := []string{"service", "pipelines", pipelineId, role}
+			errs = append(errs, checkReferencedKinds(references, path, pipelineAllowedKinds[role], components)...)
+			for _, key := range referencedComponentKeys(references) {
+				component, ok := components[key]
+				if !ok || component.Kind != "connectors" {
+					continue
+				}
+				usage, ok := connectors[key]
+				if !ok {
+					usage = &connectorUsage{}
+					connectors[key] = usage
+				}
+				switch role {
+				case "receivers":
+					usage.asReceiver = append(usage.asReceiver, pipelineId)
+				case "exporters":
+					usage.asExporter = append(usage.asExporter, pipelineId)
+				}
+			}
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(connectors)) {
+		usage := connectors[key]
+		if len(usage.asReceiver) == 0 || len(usage.asExporter) == 0 {
+			errs = append(errs, fmt.Errorf("connector '%s' must be used as an exporter in one pipeline and as a receiver in another one (exporter in: %v, receiver in: %v)", key, usage.asExporter, usage.asReceiver))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validatePipelines(service map[string]any, components map[string]*loadedComponent) error {
+	var errs []error
+	pipelines, _ := service["pipelines"].(map[string]any)
+	for _, pipelineId := range slices.Sorted(maps.Keys(pipelines)) {
+		path := []string{"service", "pipelines", pipelineId}
+		signal, _, _ := strings.Cut(pipelineId, "/")
+		if !slices.Contains(pipelineSignals, signal) {
+			errs = append(errs, fmt.Errorf("%s: unknown signal '%s', must be one of: %s", JoinPath(path), signal, strings.Join(pipelineSignals, ", ")))
+			continue
+		}
+		pipeline, _ := pipelines[pipelineId].(map[string]any)
+		for _, role := range []string{"receivers", "processors", "exporters"} {
+			references, _ := pipeline[role].([]any)
+			rolePath := append(slices.Clone(path), role)
+			if len(references) == 0 && role != "processors" {
+				errs = append(errs, fmt.Errorf("%s: at least one item is required", JoinPath(rolePath)))
+			}
+			errs = append(errs, checkDuplicatedReferences(references, rolePath)...)
+			errs = append(errs, checkSupportedSignal(references, rolePath, signal, components)...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkDuplicatedReferences(references []any, path []string) []error {
+	var errs []error
+	seen := make(map[string]bool)
+	for i, reference := range references {
+		id := fmt.Sprint(reference)
+		if seen[id] {
+			itemPath := append(slices.Clone(path), fmt.Sprintf("[%d]", i))
+			description := fmt.Sprintf("'%s'", id)
+			if key, ok := componentReferenceKey(reference); ok {
+				description = fmt.Sprintf("component '%s'", key)
+			}
+			errs = append(errs, fmt.Errorf("%s: %s is listed more than once", JoinPath(itemPath), description))
+		}
+		seen[id] = true
+	}
+	return errs
+}
+
+func checkSupportedSignal(references []any, path []string, signal string, components map[string]*loadedComponent) []error {
+	var errs []error
+	for i, reference := range references {
+		key, ok := componentReferenceKey(reference)
+		if !ok {
+			continue
+		}
+		component, ok := components[key]
+		if !ok {
+			continue
+		}
+		signals := component.Component.Metadata.Signals
+		if len(signals) > 0 && !slices.Contains(signals, signal) {
+			itemPath := append(slices.Clone(path), fmt.Sprintf("[%d]", i))
+			errs = append(errs, fmt.Errorf("%s: component '%s' doesn't support %s, it only supports: %s", JoinPath(itemPath), key, signal, strings.Join(signals, ", ")))
+		}
+	}
+	return errs
+}
+
+func checkReferencedKinds(references []any, path []string, allowedKinds []string, components map[string]*loadedComponent) []error {
+	var errs []error
+	for i, reference := range references {
+		key, ok := componentReferenceKey(reference)
+		if !ok {
+			continue
+		}
+		component, ok := components[key]
+		if !ok || !isKnownKind(component.Kind) || slices.Contains(allowedKinds, component.Kind) {
+			continue
+		}
+		itemPath := append(slices.Clone(path), fmt.Sprintf("[%d]", i))
+		errs = append(errs, fmt.Errorf("%s: component '%s' is of kind %s, only %s are allowed here", JoinPath(itemPath), key, singularKind(component.Kind), strings.Join(allowedKinds, " or ")))
+	}
+	return errs
+}
+
+func validateAuthenticators(config map[string]any, components map[string]*loadedComponent) error {
+	extensions := make(map[string]bool)
+	if service, ok := config["service"].(map[string]any); ok {
+		listed, _ := service["extensions"].([]any)
+		for _, extension := range listed {
+			extensions[fmt.Sprint(extension)] = true
+		}
+	}
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(components)) {
+		component := components[key]
+		section, _ := config[component.Kind].(map[string]any)
+		body, _ := section[component.Name].(map[string]any)
+		for _, authenticator := range findAuthenticators(body) {
+			if !extensions[authenticator] {
+				errs = append(errs, fmt.Errorf("component '%s' uses the '%s' authenticator, which is not listed in service.extensions", key, authenticator))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func findAuthenticators(value any) []string {
+	var found []string
+	switch {
+	case isMap(value):
+		mapValue := value.(map[string]any)
+		for _, k := range slices.Sorted(maps.Keys(mapValue)) {
+			if authenticator, ok := mapValue[k].(string); ok && k == "authenticator" {
+				found = append(found, authenticator)
+				continue
+			}
+			found = append(found, findAuthenticators(mapValue[k])...)
+		}
+	case isSlice(value):
+		for _, item := range value.([]any) {
+			found = append(found, findAuthenticators(item)...)
+		}
+	}
+	return found
+}
+
+func referencedComponentKeys(references []any) []string {
+	var keys []string
+	for _, reference := range references {
+		if key, ok := componentReferenceKey(reference); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func componentReferenceKey(reference any) (string, bool) {
+	text, ok := reference.(string)
+	if !ok {
+		return "", false
+	}
+	return strings.CutPrefix(text, "$components.")
+}
+
+func isKnownKind(kind string) bool {
+	return slices.Contains(componentKinds, singularKind(kind))
+}
+
+func singularKind(kind string) string {
+	return strings.TrimSuffix(kind, "s")
+}
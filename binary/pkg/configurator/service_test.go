@@ -0,0 +1,172 @@
+package configurator
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var serviceTestComponents = map[string]string{
+	"receivers/otlp.yml": `
+configurations:
+  default:
+    content: {}
+`,
+	"processors/batch.yml": `
+configurations:
+  default:
+    content: {}
+`,
+	"exporters/debug.yml": `
+metadata:
+  signals: [ traces, metrics, logs ]
+configurations:
+  default:
+    content: {}
+`,
+	"exporters/otlphttp.yml": `
+vars:
+  authenticator:
+    type: string
+configurations:
+  default:
+    content:
+      auth:
+        authenticator: $vars.authenticator
+`,
+	"connectors/spanmetrics.yml": `
+configurations:
+  default:
+    content: {}
+`,
+	"extensions/bearertokenauth.yml": `
+configurations:
+  default:
+    content: {}
+`,
+}
+
+var serviceTestRecipeComponents = `
+description: Service validation recipe
+args: {}
+components:
+  otlp:
+    source: receivers/otlp.yml
+  batch:
+    source: processors/batch.yml
+  debug:
+    source: exporters/debug.yml
+  otlphttp:
+    source: exporters/otlphttp.yml
+    vars:
+      authenticator: $components.auth
+  spanmetrics:
+    source: connectors/spanmetrics.yml
+  auth:
+    source: extensions/bearertokenauth.yml
+`
+
+func TestServiceValidation(t *testing.T) {
+	componentsDir := writeFiles(t, serviceTestComponents)
+	for _, tc := range []struct {
+		testName             string
+		service              string
+		expectedErrorMessage string
+	}{
+		{
+			testName: "valid service",
+			service: `
+service:
+  extensions: [ $components.auth ]
+  pipelines:
+    traces:
+      receivers: [ $components.otlp ]
+      processors: [ $components.batch ]
+      exporters: [ $components.spanmetrics, $components.otlphttp ]
+    metrics:
+      receivers: [ $components.spanmetrics ]
+      exporters: [ $components.debug ]
+`,
+		},
+		{
+			testName: "misplaced components",
+			service: `
+service:
+  extensions: [ $components.auth, $components.batch ]
+  pipelines:
+    traces:
+      receivers: [ $components.debug ]
+      processors: [ $components.auth ]
+      exporters: [ $components.otlp ]
+`,
+			expectedErrorMessage: strings.Join([]string{
+				"service.extensions[1]: component 'batch' is of kind processor, only extensions are allowed here",
+				"service.pipelines.traces.exporters[0]: component 'otlp' is of kind receiver, only exporters or connectors are allowed here",
+				"service.pipelines.traces.processors[0]: component 'auth' is of kind extension, only processors are allowed here",
+				"service.pipelines.traces.receivers[0]: component 'debug' is of kind exporter, only receivers or connectors are allowed here",
+			}, "\n"),
+		},
+		{
+			testName: "connector used on one side only",
+			service: `
+service:
+  extensions: [ $components.auth ]
+  pipelines:
+    traces:
+      receivers: [ $components.otlp ]
+      exporters: [ $components.spanmetrics, $components.otlphttp ]
+`,
+			expectedErrorMessage: "connector 'spanmetrics' must be used as an exporter in one pipeline and as a receiver in another one (exporter in: [traces], receiver in: [])",
+		},
+		{
+			testName: "invalid pipelines",
+			service: `
+service:
+  extensions: [ $components.auth ]
+  pipelines:
+    metric/foo:
+      receivers: [ $components.otlp ]
+      exporters: [ $components.debug ]
+    traces:
+      receivers: [ $components.otlp ]
+      processors: [ $components.batch, $components.batch ]
+      exporters: [ ]
+    profiles:
+      receivers: [ $components.otlp ]
+      exporters: [ $components.debug ]
+`,
+			expectedErrorMessage: strings.Join([]string{
+				"service.pipelines.metric/foo: unknown signal 'metric', must be one of: traces, metrics, logs, profiles",
+				"service.pipelines.profiles.exporters[0]: component 'debug' doesn't support profiles, it only supports: traces, metrics, logs",
+				"service.pipelines.traces.processors[1]: component 'batch' is listed more than once",
+				"service.pipelines.traces.exporters: at least one item is required",
+			}, "\n"),
+		},
+		{
+			testName: "authenticator not listed in extensions",
+			service: `
+service:
+  pipelines:
+    traces:
+      receivers: [ $components.otlp ]
+      exporters: [ $components.otlphttp ]
+`,
+			expectedErrorMessage: "component 'otlphttp' uses the 'bearertokenauth' authenticator, which is not listed in service.extensions",
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			recipe, err := ParseRecipe(strings.NewReader(serviceTestRecipeComponents + tc.service))
+			assert.NoError(t, err)
+			_, err = BuildRecipe(&recipe, BuildOptions{
+				Components: os.DirFS(componentsDir),
+			})
+			if tc.expectedErrorMessage != "" {
+				assert.EqualError(t, err, tc.expectedErrorMessage)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package configurator
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+type unusedReport struct {
+	Components []string
+	Args       []string
+	Consts     []string
+}
+
+// findUnused reports the components that aren't reachable from the service, either directly or via the vars of
+// other reachable components, and the args and consts that aren't referenced anywhere.
+func findUnused(recipe *Recipe) unusedReport {
+	reachable := collectPlaceholderReferences(recipe.Service, make(map[string]bool))
+	pending := slices.Sorted(maps.Keys(reachable))
+	for len(pending) > 0 {
+		key, ok := strings.CutPrefix(pending[0], "$components.")
+		pending = pending[1:]
+		component, defined := recipe.Components[key]
+		if !ok || !defined {
+			continue
+		}
+		for reference := range collectPlaceholderReferences(map[string]any(component.Vars), make(map[string]bool)) {
+			if !reachable[reference] {
+				reachable[reference] = true
+				pending = append(pending, reference)
+			}
+		}
+	}
+	references := collectPlaceholderReferences(recipe.Service, make(map[string]bool))
+	for _, v := range recipe.Components {
+		collectPlaceholderReferences(map[string]any(v.Vars), references)
+	}
+
+	return unusedReport{
+		Components: unreferencedKeys(recipe.Components, "$components.", reachable),
+		Args:       unreferencedKeys(recipe.Args, "$args.", references),
+		Consts:     unreferencedKeys(recipe.Const, "$const.", references),
+	}
+}
+
+func (r unusedReport) messages() []string {
+	var messages []string
+	for _, k := range r.Components {
+		messages = append(messages, fmt.Sprintf("component '%s' is defined but never referenced from the service or other components", k))
+	}
+	for _, k := range r.Args {
+		messages = append(messages, fmt.Sprintf("arg '%s' is defined but never used via $args.%s", k, k))
+	}
+	for _, k := range r.Consts {
+		messages = append(messages, fmt.Sprintf("const '%s' is defined but never used via $const.%s", k, k))
+	}
+	return messages
+}
+
+func collectPlaceholderReferences(value any, references map[string]bool) map[string]bool {
+	switch {
+	case isMap(value):
+		for _, v := range value.(map[string]any) {
+			collectPlaceholderReferences(v, references)
+		}
+	case isSlice(value):
+		for _, v := range value.([]any) {
+			collectPlaceholderReferences(v, references)
+		}
+	case isString(value):
+		for _, match := range anyArgPattern.FindAllString(value.(string), -1) {
+			references[match] = true
+		}
+	}
+	return references
+}
+
+func unreferencedKeys[V any](defined map[string]V, prefix string, references map[string]bool) []string {
+	var unreferenced []string
+	for _, k := range slices.Sorted(maps.Keys(defined)) {
+		if !references[prefix+k] {
+			unreferenced = append(unreferenced, k)
+		}
+	}
+	return unreferenced
+}
@@ -0,0 +1,125 @@
+package configurator
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var recipeWithUnusedItems = `
+description: Recipe with unused items
+args:
+  endpoint:
+    description: Used endpoint
+  api_key:
+    description: Unused API key
+const:
+  port: 4318
+  unused_port: 4317
+components:
+  otlp:
+    source: receivers/otlp.yml
+    vars:
+      http_port: $const.port
+  auth:
+    source: extensions/bearertokenauth.yml
+  exporter:
+    source: exporters/otlphttp.yml
+    vars:
+      endpoint: $args.endpoint
+      authenticator: $components.auth
+  unused-exporter:
+    source: exporters/debug.yml
+    vars:
+      name: $components.unused-exporter
+      authenticator: $components.unused-auth
+  unused-auth:
+    source: extensions/bearertokenauth.yml
+    name: unused
+service:
+  pipelines:
+    traces:
+      receivers: [ $components.otlp ]
+      exporters: [ $components.exporter ]
+`
+
+func TestFindUnused(t *testing.T) {
+	recipe, err := ParseRecipe(strings.NewReader(recipeWithUnusedItems))
+	assert.NoError(t, err)
+	report := findUnused(&recipe)
+	assert.Equal(t, unusedReport{
+		Components: []string{"unused-auth", "unused-exporter"},
+		Args:       []string{"api_key"},
+		Consts:     []string{"unused_port"},
+	}, report)
+	assert.Equal(t, []string{
+		"component 'unused-auth' is defined but never referenced from the service or other components",
+		"component 'unused-exporter' is defined but never referenced from the service or other components",
+		"arg 'api_key' is defined but never used via $args.api_key",
+		"const 'unused_port' is defined but never used via $const.unused_port",
+	}, report.messages())
+}
+
+func TestBuildRecipePruningUnusedComponents(t *testing.T) {
+	componentsDir := writeFiles(t, map[string]string{
+		"receivers/otlp.yml": `
+configurations:
+  default:
+    content: {}
+`,
+		"exporters/debug.yml": `
+vars:
+  receiver: none
+configurations:
+  default:
+    content: {}
+`,
+	})
+	recipe, err := ParseRecipe(strings.NewReader(`
+description: Recipe with an unused component
+args: {}
+components:
+  otlp:
+    source: receivers/otlp.yml
+  debug:
+    source: exporters/debug.yml
+  unused-otlp:
+    source: receivers/otlp.yml
+    name: unused
+  unused-debug:
+    source: exporters/debug.yml
+    name: unused
+    vars:
+      receiver: $components.unused-otlp
+service:
+  pipelines:
+    logs:
+      receivers: [ $components.otlp ]
+      exporters: [ $components.debug ]
+`))
+	assert.NoError(t, err)
+
+	var warnings []string
+	params := BuildOptions{
+		Components: os.DirFS(componentsDir),
+		Warn: func(message string) {
+			warnings = append(warnings, message)
+		},
+	}
+	data, err := BuildRecipe(&recipe, params)
+	assert.NoError(t, err)
+	assert.Contains(t, data["receivers"], "otlp/unused")
+	assert.Equal(t, []string{
+		"component 'unused-debug' is defined but never referenced from the service or other components",
+		"component 'unused-otlp' is defined but never referenced from the service or other components",
+	}, warnings)
+
+	params.PruneUnused = true
+	data, err = BuildRecipe(&recipe, params)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"otlp": map[string]any{}}, data["receivers"])
+	assert.Equal(t, map[string]any{"debug": map[string]any{}}, data["exporters"])
+	assert.Len(t, recipe.Components, 4)
+}
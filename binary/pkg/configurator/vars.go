@@ -0,0 +1,143 @@
+package configurator
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+type VarType string
+
+const (
+	VarTypeAny    VarType = "any"
+	VarTypeString VarType = "string"
+	VarTypeBool   VarType = "bool"
+	VarTypeInt    VarType = "int"
+	VarTypeFloat  VarType = "float"
+	VarTypeNumber VarType = "number"
+)
+
+// VarDecl declares a component var. Written as a plain value in YAML, it only sets the default.
+type VarDecl struct {
+	Description string
+	Type        VarType `validate:"omitempty,oneof=any string bool int float number"`
+	Required    bool
+	Default     any
+}
+
+type VarDecls map[string]VarDecl
+
+func (d *VarDecl) UnmarshalYAML(unmarshal func(any) error) error {
+	var value any
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	if !isMap(value) {
+		*d = VarDecl{Default: value}
+		return nil
+	}
+	type plainVarDecl VarDecl
+	var decl plainVarDecl
+	if err := unmarshal(&decl); err != nil {
+		return err
+	}
+	*d = VarDecl(decl)
+	return nil
+}
+
+func (d VarDecls) defaults() Vars {
+	defaults := make(Vars)
+	for k, v := range d {
+		if v.Default != nil {
+			defaults[k] = v.Default
+		}
+	}
+	return defaults
+}
+
+func (d VarDecls) checkProvided(component *Component, provided map[string]any) error {
+	known := maps.Clone(map[string]VarDecl(d))
+	if known == nil {
+		known = make(map[string]VarDecl)
+	}
+	for _, configuration := range component.Configurations {
+		for k := range configuration.Vars {
+			if _, ok := known[k]; !ok {
+				known[k] = VarDecl{}
+			}
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(provided)) {
+		if _, ok := known[k]; !ok {
+			return fmt.Errorf("unknown var '%s' provided, the declared vars are: %v", k, slices.Sorted(maps.Keys(known)))
+		}
+	}
+	return nil
+}
+
+func (d VarDecls) checkValues(values Vars) (Vars, error) {
+	checked := maps.Clone(values)
+	for _, k := range slices.Sorted(maps.Keys(d)) {
+		decl := d[k]
+		value, ok := values[k]
+		if !ok || value == nil {
+			if decl.Required {
+				return nil, fmt.Errorf("var '%s' is required but it wasn't provided", k)
+			}
+			continue
+		}
+		converted, err := convertVarValue(value, decl.Type)
+		if err != nil {
+			return nil, fmt.Errorf("var '%s' %v", k, err)
+		}
+		checked[k] = converted
+	}
+	return checked, nil
+}
+
+func convertVarValue(value any, kind VarType) (any, error) {
+	if !isPrimitive(value) {
+		if kind == "" || kind == VarTypeAny {
+			return value, nil
+		}
+		return nil, fmt.Errorf("must be of type %s, got %v", kind, getKind(value))
+	}
+	text, isText := value.(string)
+	switch kind {
+	case VarTypeString:
+		if isText {
+			return value, nil
+		}
+	case VarTypeBool:
+		if isBool(value) {
+			return value, nil
+		}
+		if parsed, err := strconv.ParseBool(text); isText && err == nil {
+			return parsed, nil
+		}
+	case VarTypeInt:
+		if isInteger(value) {
+			return value, nil
+		}
+		if parsed, err := strconv.ParseInt(text, 10, 64); isText && err == nil {
+			return parsed, nil
+		}
+	case VarTypeFloat, VarTypeNumber:
+		if isFloat(value) || (kind == VarTypeNumber && isInteger(value)) {
+			return value, nil
+		}
+		if number, ok := toFloat(value); ok && isInteger(value) {
+			return number, nil
+		}
+		if parsed, err := strconv.ParseInt(text, 10, 64); kind == VarTypeNumber && isText && err == nil {
+			return parsed, nil
+		}
+		if parsed, err := strconv.ParseFloat(text, 64); isText && err == nil {
+			return parsed, nil
+		}
+	default:
+		return value, nil
+	}
+	return nil, fmt.Errorf("must be of type %s, got %v '%v'", kind, getKind(value), value)
+}
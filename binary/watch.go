@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/elastic/edot-collector-configurator/binary/pkg/configurator"
+	"github.com/goccy/go-yaml"
+)
+
+const watchInterval = 500 * time.Millisecond
+
+// trackedFS records the files opened through it, as paths joined with its root directory.
+type trackedFS struct {
+	fs.FS
+	root   string
+	opened map[string]bool
+}
+
+func newTrackedFS(root string) *trackedFS {
+	return &trackedFS{FS: os.DirFS(root), root: root, opened: make(map[string]bool)}
+}
+
+func (t *trackedFS) Open(name string) (fs.File, error) {
+	t.opened[filepath.Join(t.root, filepath.FromSlash(name))] = true
+	return t.FS.Open(name)
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+type recipeWatcher struct {
+	recipePath        string
+	outputPath        string
+	command           string
+	componentsDirPath string
+	schemasDirPath    string
+	options           configurator.BuildOptions
+	out               io.Writer
+	stat              func(path string) (fs.FileInfo, error)
+	run               func(command string) error
+	tracked           []string
+	states            map[string]fileState
+	lastBuilt         any
+	hasBuilt          bool
+}
+
+func newRecipeWatcher(recipePath string, outputPath string, command string, schemasDirPath string, options configurator.BuildOptions) *recipeWatcher {
+	return &recipeWatcher{
+		recipePath:        recipePath,
+		outputPath:        outputPath,
+		command:           command,
+		componentsDirPath: getComponentsDirPath(),
+		schemasDirPath:    schemasDirPath,
+		options:           options,
+		out:               os.Stdout,
+		stat:              os.Stat,
+		run: func(command string) error {
+			cmd := exec.Command("sh", "-c", command)
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			return cmd.Run()
+		},
+	}
+}
+
+// watchRecipe builds the recipe, then rebuilds it every time the recipe or any file read while building it (component
+// sources and schemas) changes, until the process is interrupted. Failed builds keep the previous output.
+func watchRecipe(recipePath string, outputPath string, command string, schemasDirPath string, options configurator.BuildOptions) {
+	newRecipeWatcher(recipePath, outputPath, command, schemasDirPath, options).watch(time.Tick(watchInterval))
+}
+
+func (w *recipeWatcher) watch(ticks <-chan time.Time) {
+	w.build()
+	fmt.Fprintf(w.out, "watching %d files, press Ctrl+C to stop\n", len(w.tracked))
+	for now := range ticks {
+		w.poll(now)
+	}
+}
+
+// poll rebuilds the recipe when any of the tracked files changed since the last build, and runs the command when the
+// rebuild succeeds.
+func (w *recipeWatcher) poll(now time.Time) {
+	changed := w.changedFiles(w.statFiles(w.tracked))
+	if len(changed) == 0 {
+		return
+	}
+	fmt.Fprintf(w.out, "\n[%s] changed: %s\n", now.Format(time.TimeOnly), relativeToWorkingDirs(changed))
+	if w.build() && w.command != "" {
+		w.runCommand()
+	}
+}
+
+func (w *recipeWatcher) statFiles(paths []string) map[string]fileState {
+	states := make(map[string]fileState, len(paths))
+	for _, path := range paths {
+		info, err := w.stat(path)
+		if err != nil {
+			states[path] = fileState{}
+			continue
+		}
+		states[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+	return states
+}
+
+func (w *recipeWatcher) changedFiles(states map[string]fileState) []string {
+	var changed []string
+	for _, path := range w.tracked {
+		if states[path] != w.states[path] {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+func (w *recipeWatcher) build() bool {
+	componentsFS := newTrackedFS(w.componentsDirPath)
+	options := w.options
+	options.Components = componentsFS
+	var schemasFS *trackedFS
+	if w.schemasDirPath != "" {
+		schemasFS = newTrackedFS(w.schemasDirPath)
+		options.Schemas = schemasFS
+	}
+	data, configuration, err := w.buildRecipe(options)
+
+	tracked := maps.Clone(componentsFS.opened)
+	if schemasFS != nil {
+		maps.Copy(tracked, schemasFS.opened)
+	}
+	recipePath, absErr := filepath.Abs(w.recipePath)
+	if absErr != nil {
+		recipePath = w.recipePath
+	}
+	tracked[recipePath] = true
+	w.tracked = slices.Sorted(maps.Keys(tracked))
+	w.states = w.statFiles(w.tracked)
+
+	if err != nil {
+		fmt.Fprintf(w.out, "error: %v\nbuild failed, keeping the previous configuration\n", err)
+		return false
+	}
+	if w.hasBuilt {
+		output, err := configurator.FormatDiff(configurator.Diff(w.lastBuilt, configuration), "text")
+		checkUnexpectedError(err)
+		fmt.Fprint(w.out, string(output))
+	}
+	if err := os.WriteFile(w.outputPath, data, 0644); err != nil {
+		fmt.Fprintf(w.out, "error: %v\n", err)
+		return false
+	}
+	w.lastBuilt, w.hasBuilt = configuration, true
+	fmt.Fprintf(w.out, "build succeeded, wrote %s\n", w.outputPath)
+	return true
+}
+
+func (w *recipeWatcher) buildRecipe(options configurator.BuildOptions) ([]byte, any, error) {
+	recipe, err := configurator.LoadRecipe(os.DirFS(filepath.Dir(w.recipePath)), filepath.Base(w.recipePath))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not load recipe '%s': %w", w.recipePath, err)
+	}
+	data, err := configurator.BuildRecipeYaml(&recipe, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	var configuration any
+	if err := yaml.Unmarshal(data, &configuration); err != nil {
+		return nil, nil, err
+	}
+	return data, configuration, nil
+}
+
+func (w *recipeWatcher) runCommand() {
+	fmt.Fprintf(w.out, "running: %s\n", w.command)
+	if err := w.run(w.command); err != nil {
+		fmt.Fprintf(w.out, "error: command failed: %v\n", err)
+	}
+}
+
+func relativeToWorkingDirs(paths []string) string {
+	relative := make([]string, len(paths))
+	for i, path := range paths {
+		relative[i] = relativeToWorkingDir(path)
+	}
+	return strings.Join(relative, ", ")
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/edot-collector-configurator/binary/pkg/configurator"
+	"github.com/stretchr/testify/assert"
+)
+
+type versionedFileInfo struct {
+	fs.FileInfo
+	version int
+}
+
+func (v versionedFileInfo) ModTime() time.Time {
+	return time.Unix(int64(v.version), 0)
+}
+
+func TestRecipeWatcher(t *testing.T) {
+	dir := t.TempDir()
+	versions := make(map[string]int)
+	writeFile := func(path string, content string) {
+		fullPath := filepath.Join(dir, path)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		assert.NoError(t, os.WriteFile(fullPath, []byte(content), 0644))
+		versions[fullPath]++
+	}
+	writeFile("components/receivers/otlp.yml", `
+configurations:
+  default:
+    content:
+      protocols: { grpc: { endpoint: "localhost:4317" } }
+`)
+	writeFile("components/exporters/debug.yml", `
+vars:
+  verbosity: basic
+configurations:
+  default:
+    content:
+      verbosity: $vars.verbosity
+`)
+	writeFile("schemas/exporters/debug.schema.json", `{ "properties": { "verbosity": { "enum": [ "basic", "detailed" ] } } }`)
+	recipe := `
+description: Watched recipe
+args: {}
+components:
+  otlp:
+    source: receivers/otlp.yml
+  debug:
+    source: exporters/debug.yml
+    vars:
+      verbosity: basic
+service:
+  pipelines:
+    traces:
+      receivers: [ $components.otlp ]
+      exporters: [ $components.debug ]
+`
+	writeFile("recipe.yml", recipe)
+	outputPath := filepath.Join(dir, "otel.yml")
+
+	var out strings.Builder
+	var commands []string
+	w := newRecipeWatcher(filepath.Join(dir, "recipe.yml"), outputPath, "reload", filepath.Join(dir, "schemas"), configurator.BuildOptions{})
+	w.componentsDirPath = filepath.Join(dir, "components")
+	w.out = &out
+	w.stat = func(path string) (fs.FileInfo, error) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		return versionedFileInfo{FileInfo: info, version: versions[path]}, nil
+	}
+	w.run = func(command string) error {
+		commands = append(commands, command)
+		return nil
+	}
+	readOutput := func() string {
+		data, err := os.ReadFile(outputPath)
+		assert.NoError(t, err)
+		return string(data)
+	}
+
+	ticks := make(chan time.Time)
+	close(ticks)
+	w.watch(ticks)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "components/exporters/debug.yml"),
+		filepath.Join(dir, "components/receivers/otlp.yml"),
+		filepath.Join(dir, "recipe.yml"),
+		filepath.Join(dir, "schemas/exporters/debug.schema.json"),
+		filepath.Join(dir, "schemas/receivers/otlp.schema.json"),
+	}, w.tracked)
+	assert.Contains(t, out.String(), "watching 5 files")
+	assert.Contains(t, readOutput(), "verbosity: basic")
+
+	w.poll(time.Now())
+	assert.Empty(t, commands)
+
+	for _, tc := range []struct {
+		testName         string
+		path             string
+		content          string
+		expectedOutput   string
+		expectedMessage  string
+		expectedCommands []string
+	}{
+		{
+			testName:         "recipe change",
+			path:             "recipe.yml",
+			content:          strings.Replace(recipe, "verbosity: basic", "verbosity: detailed", 1),
+			expectedOutput:   "verbosity: detailed",
+			expectedMessage:  "build succeeded",
+			expectedCommands: []string{"reload"},
+		},
+		{
+			testName:         "component change",
+			path:             "components/receivers/otlp.yml",
+			content:          "configurations:\n  default:\n    content:\n      protocols: { http: {} }\n",
+			expectedOutput:   "http: {}",
+			expectedMessage:  "build succeeded",
+			expectedCommands: []string{"reload", "reload"},
+		},
+		{
+			testName:         "new schema",
+			path:             "schemas/receivers/otlp.schema.json",
+			content:          `{ "required": [ "protocols" ] }`,
+			expectedOutput:   "http: {}",
+			expectedMessage:  "build succeeded",
+			expectedCommands: []string{"reload", "reload", "reload"},
+		},
+		{
+			testName:         "failed build keeps the previous output",
+			path:             "schemas/exporters/debug.schema.json",
+			content:          `{ "properties": { "verbosity": { "enum": [ "basic" ] } } }`,
+			expectedOutput:   "verbosity: detailed",
+			expectedMessage:  "build failed, keeping the previous configuration",
+			expectedCommands: []string{"reload", "reload", "reload"},
+		},
+		{
+			testName:         "schema change",
+			path:             "schemas/exporters/debug.schema.json",
+			content:          `{ "properties": { "verbosity": { "enum": [ "detailed" ] } } }`,
+			expectedOutput:   "verbosity: detailed",
+			expectedMessage:  "build succeeded",
+			expectedCommands: []string{"reload", "reload", "reload", "reload"},
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			out.Reset()
+			writeFile(tc.path, tc.content)
+			w.poll(time.Now())
+			assert.Contains(t, out.String(), "changed: "+relativeToWorkingDir(filepath.Join(dir, tc.path)))
+			assert.Contains(t, out.String(), tc.expectedMessage)
+			assert.Contains(t, readOutput(), tc.expectedOutput)
+			assert.Equal(t, tc.expectedCommands, commands)
+		})
+	}
+}